@@ -0,0 +1,119 @@
+package testhelper
+
+import (
+	"errors"
+	"testing"
+)
+
+// CheckVals compares the actual and expected values using the DiffValsAll
+// func and reports any differences found as test errors. The ID is taken
+// from the TestCase and the name describes the values being compared. The
+// ignore parameter is as for the DiffVals func. Each difference is
+// reported with its path and the values found there.
+//
+// Unlike the other Check... funcs, but in the same way as the Diff... funcs,
+// it returns true if the values differ and false otherwise.
+func CheckVals(t testing.TB, tc TestCase, name string, act, exp any,
+	ignore ...[]string,
+) bool {
+	t.Helper()
+
+	dvc := DiffValsCfg{ReportAll: true}
+
+	return reportDiffVals(t, tc.IDStr(), tcLocation(tc), name, act, exp,
+		dvc.diffVals(act, exp, ignore))
+}
+
+// CheckValsWithID compares the actual and expected values in the same way
+// as CheckVals but the test ID is supplied separately.
+func CheckValsWithID(t testing.TB, id, name string, act, exp any,
+	ignore ...[]string,
+) bool {
+	t.Helper()
+
+	dvc := DiffValsCfg{ReportAll: true}
+
+	return reportDiffVals(t, id, "", name, act, exp,
+		dvc.diffVals(act, exp, ignore))
+}
+
+// CheckVals compares the actual and expected values using the DiffValsCfg
+// and reports any differences found as test errors in the same way as the
+// CheckVals func. All the differences are reported whether or not the
+// ReportAll flag is set. It returns true if the values differ and false
+// otherwise.
+func (dvc DiffValsCfg) CheckVals(t testing.TB, tc TestCase, name string,
+	act, exp any,
+) bool {
+	t.Helper()
+
+	dvc.ReportAll = true
+
+	return reportDiffVals(t, tc.IDStr(), tcLocation(tc), name, act, exp,
+		dvc.DiffVals(act, exp))
+}
+
+// CheckValsWithID compares the actual and expected values using the
+// DiffValsCfg in the same way as the CheckVals method but the test ID is
+// supplied separately.
+func (dvc DiffValsCfg) CheckValsWithID(t testing.TB, id, name string,
+	act, exp any,
+) bool {
+	t.Helper()
+
+	dvc.ReportAll = true
+
+	return reportDiffVals(t, id, "", name, act, exp, dvc.DiffVals(act, exp))
+}
+
+// reportDiffVals reports the differences found by DiffVals between the
+// actual and expected values. The location (at) of the test case is given
+// if it is known. It returns true if there were any differences, false
+// otherwise.
+func reportDiffVals(t testing.TB, id, at, name string, act, exp any,
+	err error,
+) bool {
+	t.Helper()
+
+	if err == nil {
+		return false
+	}
+
+	f := newFailure(id, "CheckVals", name)
+	f.At = at
+	f.Act, f.Exp = PrettyPrint(act), PrettyPrint(exp)
+
+	var dves DiffValErrs
+	if !errors.As(err, &dves) {
+		f.logf("\t: %s\n", err)
+		f.report(t, "\t: %s is incorrect\n", name)
+
+		return true
+	}
+
+	if dves.Count == 1 {
+		f.logf("\t: %s differs in 1 place\n", name)
+	} else {
+		f.logf("\t: %s differs in %d places\n", name, dves.Count)
+	}
+
+	for _, dve := range dves.Errs {
+		f.logf("\t: %s\n", dve)
+
+		if dve.Exp.IsValid() {
+			f.logf("\t\t: expected: %s\n", prettyPrintVal(dve.Exp))
+		}
+
+		if dve.Act.IsValid() {
+			f.logf("\t\t:   actual: %s\n", prettyPrintVal(dve.Act))
+		}
+	}
+
+	if hidden := dves.Count - len(dves.Errs); hidden > 0 {
+		f.logf("\t: ... %d more differences not shown\n", hidden)
+	}
+
+	f.report(t, "\t: %s is incorrect\n", name)
+
+	return true
+}
@@ -0,0 +1,149 @@
+package testhelper_test
+
+import (
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+// checkValsNested exists only to test the reports from CheckVals
+type checkValsNested struct {
+	Name  string
+	Tags  map[string][]int
+	Items []cfgTestItem
+}
+
+func TestCheckVals(t *testing.T) {
+	actVal := checkValsNested{
+		Name:  "a",
+		Tags:  map[string][]int{"x": {1, 2}, "y": {3}},
+		Items: []cfgTestItem{{ID: 1, Name: "i1"}, {ID: 2, Name: "i2"}},
+	}
+
+	testCases := []struct {
+		testhelper.ID
+		actVal    any
+		expVal    any
+		ignore    [][]string
+		cfgIgnore []string
+		expLog    string
+	}{
+		{
+			ID: testhelper.MkID("both nil"),
+		},
+		{
+			ID:     testhelper.MkID("same values"),
+			actVal: cfgTestItem{ID: 1, Name: "a"},
+			expVal: cfgTestItem{ID: 1, Name: "a"},
+		},
+		{
+			ID:     testhelper.MkID("differences ignored"),
+			actVal: cfgTestItem{ID: 1, Name: "a"},
+			expVal: cfgTestItem{ID: 2, Name: "a"},
+			ignore: [][]string{{"ID"}},
+		},
+		{
+			ID:        testhelper.MkID("ignored by the DiffValsCfg"),
+			actVal:    cfgTestItem{ID: 1, Name: "a"},
+			expVal:    cfgTestItem{ID: 2, Name: "a"},
+			cfgIgnore: []string{"ID"},
+		},
+		{
+			ID:     testhelper.MkID("one difference"),
+			actVal: actVal,
+			expVal: checkValsNested{
+				Name:  "b",
+				Tags:  map[string][]int{"x": {1, 2}, "y": {3}},
+				Items: []cfgTestItem{{ID: 1, Name: "i1"}, {ID: 2, Name: "i2"}},
+			},
+			expLog: "id\n" +
+				"\t: value differs in 1 place\n" +
+				"\t: this.Name: strings differ." +
+				" Actual: \"a\", expected: \"b\"\n" +
+				"\t\t: expected: \"b\"\n" +
+				"\t\t:   actual: \"a\"\n" +
+				"\t: value is incorrect\n",
+		},
+		{
+			ID:     testhelper.MkID("nested differences"),
+			actVal: actVal,
+			expVal: checkValsNested{
+				Name:  "a",
+				Tags:  map[string][]int{"x": {1, 4}, "z": {3}},
+				Items: []cfgTestItem{{ID: 1, Name: "i1"}, {ID: 3, Name: "i2"}},
+			},
+			expLog: "id\n" +
+				"\t: value differs in 3 places\n" +
+				"\t: this.Tags: map keys differ." +
+				" Present in actual but not expected: [\"y\"]," +
+				" Missing from actual: [\"z\"]\n" +
+				"\t\t: expected: map[string][]int{" +
+				"\"x\": []int{1, 4}, \"z\": []int{3}}\n" +
+				"\t\t:   actual: map[string][]int{" +
+				"\"x\": []int{1, 2}, \"y\": []int{3}}\n" +
+				"\t: this.Tags[\"x\"][1]: int values differ." +
+				" Actual: 2, expected: 4\n" +
+				"\t\t: expected: 4\n" +
+				"\t\t:   actual: 2\n" +
+				"\t: this.Items[1].ID: int values differ." +
+				" Actual: 2, expected: 3\n" +
+				"\t\t: expected: 3\n" +
+				"\t\t:   actual: 2\n" +
+				"\t: value is incorrect\n",
+		},
+		{
+			ID:     testhelper.MkID("too many differences"),
+			actVal: actVal,
+			expVal: checkValsNested{
+				Name:  "b",
+				Tags:  map[string][]int{"x": {2, 1}, "y": {4}},
+				Items: []cfgTestItem{{ID: 2, Name: "j1"}, {ID: 3, Name: "i2"}},
+			},
+			expLog: "id\n" +
+				"\t: value differs in 7 places\n" +
+				"\t: this.Name: strings differ." +
+				" Actual: \"a\", expected: \"b\"\n" +
+				"\t\t: expected: \"b\"\n" +
+				"\t\t:   actual: \"a\"\n" +
+				"\t: this.Tags[\"x\"][0]: int values differ." +
+				" Actual: 1, expected: 2\n" +
+				"\t\t: expected: 2\n" +
+				"\t\t:   actual: 1\n" +
+				"\t: this.Tags[\"x\"][1]: int values differ." +
+				" Actual: 2, expected: 1\n" +
+				"\t\t: expected: 1\n" +
+				"\t\t:   actual: 2\n" +
+				"\t: this.Tags[\"y\"][0]: int values differ." +
+				" Actual: 3, expected: 4\n" +
+				"\t\t: expected: 4\n" +
+				"\t\t:   actual: 3\n" +
+				"\t: this.Items[0].ID: int values differ." +
+				" Actual: 1, expected: 2\n" +
+				"\t\t: expected: 2\n" +
+				"\t\t:   actual: 1\n" +
+				"\t: ... 2 more differences not shown\n" +
+				"\t: value is incorrect\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		ft := &fakeTB{TB: t}
+
+		var reported bool
+
+		if tc.cfgIgnore != nil {
+			dvc := testhelper.DiffValsCfg{}
+			if err := dvc.AddIgnore(tc.cfgIgnore...); err != nil {
+				t.Fatal("unexpected error: ", err)
+			}
+
+			reported = dvc.CheckValsWithID(ft, "id", "value",
+				tc.actVal, tc.expVal)
+		} else {
+			reported = testhelper.CheckValsWithID(ft, "id", "value",
+				tc.actVal, tc.expVal, tc.ignore...)
+		}
+
+		ft.CheckResult(t, tc.IDStr(), reported, tc.expLog)
+	}
+}
@@ -0,0 +1,159 @@
+package testhelper
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+// ratDecimalPlaces is the number of decimal places shown when reporting a
+// big.Rat value which is not an integer
+const ratDecimalPlaces = 20
+
+// reportBigNilDiff reports the difference between two values where just one
+// of them is nil. It returns false if neither or both are nil.
+func reportBigNilDiff(t testing.TB, id, kind, name string,
+	actIsNil, expIsNil bool,
+) bool {
+	t.Helper()
+
+	if actIsNil == expIsNil {
+		return false
+	}
+
+	f := newFailure(id, kind, name)
+
+	if actIsNil {
+		f.Act = "nil"
+		f.logf("\t: expected %s is non-nil\n", name)
+		f.logf("\t:   actual %s is nil\n", name)
+	} else {
+		f.Exp = "nil"
+		f.logf("\t: expected %s is nil\n", name)
+		f.logf("\t:   actual %s is non-nil\n", name)
+	}
+
+	f.report(t, "\t: %s is incorrect\n", name)
+
+	return true
+}
+
+// reportBigDiff reports the difference between two big number values which
+// have been converted to strings
+func reportBigDiff(t testing.TB, id, kind, name, act, exp, diff string) {
+	t.Helper()
+
+	f := newStrFailure(id, kind, name, act, exp)
+	f.logf("\t: expected %s: %s\n", name, exp)
+	f.logf("\t:   actual %s: %s\n", name, act)
+	charCnt := len(name) + len("expected") + 1
+	f.logf("\t: %*s: %s\n", charCnt, "diff", diff)
+	f.report(t, "\t: %s is incorrect\n", name)
+}
+
+// DiffBigInt compares the actual against the expected value and reports an
+// error if they differ. The values are compared by value (using the Cmp
+// method) and reported in decimal form. It will report them as different
+// if one is nil and the other isn't.
+func DiffBigInt(t testing.TB, id, name string, act, exp *big.Int) bool {
+	t.Helper()
+
+	if act == nil || exp == nil {
+		return reportBigNilDiff(t, id, "DiffBigInt", name,
+			act == nil, exp == nil)
+	}
+
+	if act.Cmp(exp) == 0 {
+		return false
+	}
+
+	reportBigDiff(t, id, "DiffBigInt", name, act.String(), exp.String(),
+		new(big.Int).Sub(act, exp).String())
+
+	return true
+}
+
+// DiffBigFloat compares the actual against the expected value and reports
+// an error if they differ. The values are compared by value (using the Cmp
+// method) so values with different precisions but the same value are
+// taken to be the same. The values are reported in decimal form with as
+// many digits as are needed to represent them exactly. It will report them
+// as different if one is nil and the other isn't.
+func DiffBigFloat(t testing.TB, id, name string, act, exp *big.Float) bool {
+	t.Helper()
+
+	if act == nil || exp == nil {
+		return reportBigNilDiff(t, id, "DiffBigFloat", name,
+			act == nil, exp == nil)
+	}
+
+	if act.Cmp(exp) == 0 {
+		return false
+	}
+
+	diff := new(big.Float).SetPrec(max(act.Prec(), exp.Prec())).
+		Sub(act, exp)
+
+	reportBigDiff(t, id, "DiffBigFloat", name,
+		act.Text('g', -1), exp.Text('g', -1), diff.Text('g', -1))
+
+	return true
+}
+
+// DiffBigRat compares the actual against the expected value and reports an
+// error if they differ. The values are compared by value (using the Cmp
+// method) and reported as fractions and, if they are not integers, in
+// decimal form. It will report them as different if one is nil and the
+// other isn't.
+func DiffBigRat(t testing.TB, id, name string, act, exp *big.Rat) bool {
+	t.Helper()
+
+	if act == nil || exp == nil {
+		return reportBigNilDiff(t, id, "DiffBigRat", name,
+			act == nil, exp == nil)
+	}
+
+	if act.Cmp(exp) == 0 {
+		return false
+	}
+
+	reportBigDiff(t, id, "DiffBigRat", name,
+		ratStr(act), ratStr(exp), ratStr(new(big.Rat).Sub(act, exp)))
+
+	return true
+}
+
+// ratStr returns the big.Rat as a fraction and, if it is not an integer,
+// in decimal form
+func ratStr(r *big.Rat) string {
+	if r.IsInt() {
+		return r.RatString()
+	}
+
+	return r.RatString() + " (" + r.FloatString(ratDecimalPlaces) + ")"
+}
+
+// bigCmpFunc returns a func comparing values of the type by value if it is
+// one of the math/big number types, *big.Int, *big.Float or *big.Rat. For
+// any other type it returns nil.
+func bigCmpFunc(t reflect.Type) func(act, exp reflect.Value) bool {
+	switch t {
+	case reflect.TypeFor[*big.Int]():
+		return bigCmp[*big.Int]
+	case reflect.TypeFor[*big.Float]():
+		return bigCmp[*big.Float]
+	case reflect.TypeFor[*big.Rat]():
+		return bigCmp[*big.Rat]
+	}
+
+	return nil
+}
+
+// bigCmp returns true if the values compare as equal using their Cmp
+// method
+func bigCmp[T interface{ Cmp(T) int }](act, exp reflect.Value) bool {
+	actT, _ := act.Interface().(T)
+	expT, _ := exp.Interface().(T)
+
+	return actT.Cmp(expT) == 0
+}
@@ -0,0 +1,253 @@
+package testhelper_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestDiffBig(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		f      func(t testing.TB) bool
+		expLog string
+	}{
+		{
+			ID: testhelper.MkID("big.Int, same"),
+			f: func(t testing.TB) bool {
+				return testhelper.DiffBigInt(t, "id", "value",
+					big.NewInt(42), new(big.Int).SetInt64(42))
+			},
+		},
+		{
+			ID: testhelper.MkID("big.Int, both nil"),
+			f: func(t testing.TB) bool {
+				return testhelper.DiffBigInt(t, "id", "value", nil, nil)
+			},
+		},
+		{
+			ID: testhelper.MkID("big.Float, different precision"),
+			f: func(t testing.TB) bool {
+				return testhelper.DiffBigFloat(t, "id", "value",
+					new(big.Float).SetPrec(200).SetFloat64(1.5),
+					big.NewFloat(1.5))
+			},
+		},
+		{
+			ID: testhelper.MkID("big.Rat, not normalised"),
+			f: func(t testing.TB) bool {
+				return testhelper.DiffBigRat(t, "id", "value",
+					big.NewRat(2, 6), big.NewRat(1, 3))
+			},
+		},
+		{
+			ID: testhelper.MkID("big.Int"),
+			f: func(t testing.TB) bool {
+				return testhelper.DiffBigInt(t, "id", "value",
+					big.NewInt(40), big.NewInt(42))
+			},
+			expLog: "id\n" +
+				"\t: expected value: 42\n" +
+				"\t:   actual value: 40\n" +
+				"\t:           diff: -2\n" +
+				"\t: value is incorrect\n",
+		},
+		{
+			ID: testhelper.MkID("big.Int, actual nil"),
+			f: func(t testing.TB) bool {
+				return testhelper.DiffBigInt(t, "id", "value",
+					nil, big.NewInt(42))
+			},
+			expLog: "id\n" +
+				"\t: expected value is non-nil\n" +
+				"\t:   actual value is nil\n" +
+				"\t: value is incorrect\n",
+		},
+		{
+			ID: testhelper.MkID("big.Float"),
+			f: func(t testing.TB) bool {
+				return testhelper.DiffBigFloat(t, "id", "value",
+					big.NewFloat(1.5), big.NewFloat(1.25))
+			},
+			expLog: "id\n" +
+				"\t: expected value: 1.25\n" +
+				"\t:   actual value: 1.5\n" +
+				"\t:           diff: 0.25\n" +
+				"\t: value is incorrect\n",
+		},
+		{
+			ID: testhelper.MkID("big.Float, expected nil"),
+			f: func(t testing.TB) bool {
+				return testhelper.DiffBigFloat(t, "id", "value",
+					big.NewFloat(1.5), nil)
+			},
+			expLog: "id\n" +
+				"\t: expected value is nil\n" +
+				"\t:   actual value is non-nil\n" +
+				"\t: value is incorrect\n",
+		},
+		{
+			ID: testhelper.MkID("big.Rat"),
+			f: func(t testing.TB) bool {
+				return testhelper.DiffBigRat(t, "id", "value",
+					big.NewRat(1, 3), big.NewRat(1, 4))
+			},
+			expLog: "id\n" +
+				"\t: expected value: 1/4 (0.25000000000000000000)\n" +
+				"\t:   actual value: 1/3 (0.33333333333333333333)\n" +
+				"\t:           diff: 1/12 (0.08333333333333333333)\n" +
+				"\t: value is incorrect\n",
+		},
+		{
+			ID: testhelper.MkID("big.Rat, integers"),
+			f: func(t testing.TB) bool {
+				return testhelper.DiffBigRat(t, "id", "value",
+					big.NewRat(4, 2), big.NewRat(3, 1))
+			},
+			expLog: "id\n" +
+				"\t: expected value: 3\n" +
+				"\t:   actual value: 2\n" +
+				"\t:           diff: -1\n" +
+				"\t: value is incorrect\n",
+		},
+		{
+			ID: testhelper.MkID("big.Rat, actual nil"),
+			f: func(t testing.TB) bool {
+				return testhelper.DiffBigRat(t, "id", "value",
+					nil, big.NewRat(1, 4))
+			},
+			expLog: "id\n" +
+				"\t: expected value is non-nil\n" +
+				"\t:   actual value is nil\n" +
+				"\t: value is incorrect\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		ft := &fakeTB{TB: t}
+		ft.CheckResult(t, tc.IDStr(), tc.f(ft), tc.expLog)
+	}
+}
+
+func TestDiffComplex(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		f      func(t testing.TB) bool
+		expLog string
+	}{
+		{
+			ID: testhelper.MkID("complex128, within tolerance"),
+			f: func(t testing.TB) bool {
+				return testhelper.DiffComplex(t, "id", "value",
+					complex(1, 2), complex(1.05, 2),
+					testhelper.FloatTol{Abs: 0.1})
+			},
+		},
+		{
+			ID: testhelper.MkID("complex64, same"),
+			f: func(t testing.TB) bool {
+				return testhelper.DiffComplex(t, "id", "value",
+					complex64(complex(1, 2)), complex64(complex(1, 2)),
+					testhelper.FloatTol{})
+			},
+		},
+		{
+			ID: testhelper.MkID("exact"),
+			f: func(t testing.TB) bool {
+				return testhelper.DiffComplex(t, "id", "value",
+					complex(1, 2), complex(1, 3), testhelper.FloatTol{})
+			},
+			expLog: "id\n" +
+				"\t: expected value: (1+3i)\n" +
+				"\t:   actual value: (1+2i)\n" +
+				"\t:           diff: (0-1i)\n" +
+				"\t: value is incorrect\n",
+		},
+		{
+			ID: testhelper.MkID("outside tolerance"),
+			f: func(t testing.TB) bool {
+				return testhelper.DiffComplex(t, "id", "value",
+					complex(1.5, 2), complex(1, 2),
+					testhelper.FloatTol{Abs: 0.1})
+			},
+			expLog: "id\n" +
+				"\t: tolerance: abs: 0.1, rel: 0\n" +
+				"\t: expected value: (1+2i)\n" +
+				"\t:   actual value: (1.5+2i)\n" +
+				"\t:           diff: (0.5+0i)\n" +
+				"\t: value is incorrect\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		ft := &fakeTB{TB: t}
+		ft.CheckResult(t, tc.IDStr(), tc.f(ft), tc.expLog)
+	}
+}
+
+func TestDiffValsBig(t *testing.T) {
+	type bigVals struct {
+		I *big.Int
+		F *big.Float
+		R *big.Rat
+	}
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		byValue bool
+		actVal  bigVals
+		expVal  bigVals
+	}{
+		{
+			ID:      testhelper.MkID("same values, different representations"),
+			byValue: true,
+			actVal: bigVals{
+				I: new(big.Int).Sub(big.NewInt(50), big.NewInt(8)),
+				F: new(big.Float).SetPrec(200).SetFloat64(1.5),
+				R: big.NewRat(2, 6),
+			},
+			expVal: bigVals{
+				I: big.NewInt(42),
+				F: big.NewFloat(1.5),
+				R: big.NewRat(1, 3),
+			},
+		},
+		{
+			ID:     testhelper.MkID("different representations, by content"),
+			actVal: bigVals{F: new(big.Float).SetPrec(200).SetFloat64(1.5)},
+			expVal: bigVals{F: big.NewFloat(1.5)},
+			ExpErr: testhelper.MkExpErr("this.F.prec: uint values differ."),
+		},
+		{
+			ID:      testhelper.MkID("both nil"),
+			byValue: true,
+			actVal:  bigVals{I: big.NewInt(1)},
+			expVal:  bigVals{I: big.NewInt(1)},
+		},
+		{
+			ID:      testhelper.MkID("different ints"),
+			byValue: true,
+			actVal:  bigVals{I: big.NewInt(41)},
+			expVal:  bigVals{I: big.NewInt(42)},
+			ExpErr: testhelper.MkExpErr(
+				"this.I: *big.Int values differ" +
+					" (compared using the Cmp method)." +
+					` Actual: *big.Int("41"), expected: *big.Int("42")`),
+		},
+		{
+			ID:     testhelper.MkID("one nil"),
+			actVal: bigVals{R: big.NewRat(1, 2)},
+			expVal: bigVals{},
+			ExpErr: testhelper.MkExpErr(
+				"this.R: the expected value is invalid"),
+		},
+	}
+
+	for _, tc := range testCases {
+		dvc := testhelper.DiffValsCfg{CompareBigByValue: tc.byValue}
+		err := dvc.DiffVals(tc.actVal, tc.expVal)
+		testhelper.CheckExpErr(t, err, tc)
+	}
+}
@@ -1,48 +1,95 @@
 package testhelper
 
 import (
+	"cmp"
 	"fmt"
 	"math"
 	"reflect"
+	"slices"
 	"testing"
 	"time"
 
 	"golang.org/x/exp/constraints"
 )
 
-// almostEqual returns true if a and b are within epsilon of one
-// another. Copied from github.com/nickwells/mathutil.mod/mathutil
+// almostEqual returns true if a and b are within epsilon of one another,
+// that is, if they are equal according to a FloatTol with an absolute
+// tolerance of epsilon.
 func almostEqual[T constraints.Float](a, b, epsilon T) bool {
-	if a == b {
-		return true
+	return tolEqual(a, b, FloatTol{Abs: float64(epsilon)})
+}
+
+// floatBits returns the size in bits of the float type
+func floatBits[T constraints.Float]() int {
+	return reflect.TypeFor[T]().Bits()
+}
+
+// tolEqual returns true if a and b are equal within the tolerances
+func tolEqual[T constraints.Float](a, b T, ft FloatTol) bool {
+	return ft.equalBits(float64(a), float64(b), floatBits[T]())
+}
+
+// relDiff returns the difference between the two values relative to the
+// larger of their absolute values. This is the measure that is compared
+// with the relative tolerance of a FloatTol.
+func relDiff(a, b float64) float64 {
+	diff := math.Abs(a - b)
+	if diff == 0 {
+		return 0
 	}
 
-	return math.Abs(float64(a-b)) < float64(epsilon)
+	return diff / math.Max(math.Abs(a), math.Abs(b))
 }
 
-// reportFloatDiff reports the difference between two float values
-func reportFloatDiff[T constraints.Float](t *testing.T, name string,
+// reportFloatDiff adds the difference between two float values to the
+// Failure
+func reportFloatDiff[T constraints.Float](f *Failure, name string,
 	act, exp T,
 ) {
-	t.Helper()
-
-	t.Logf("\t: expected %s: %5g\n", name, exp)
-	t.Logf("\t:   actual %s: %5g\n", name, act)
+	f.logf("\t: expected %s: %5g\n", name, exp)
+	f.logf("\t:   actual %s: %5g\n", name, act)
 	charCnt := len(name) + len("expected") + 1
-	t.Logf("\t: %*s: %5g\n", charCnt, "diff", math.Abs(float64(act-exp)))
-	t.Errorf("\t: %s is incorrect\n", name)
+	f.logf("\t: %*s: %5g\n", charCnt, "diff", math.Abs(float64(act-exp)))
+	f.logf("\t: %*s: %5g\n", charCnt, "rel diff",
+		relDiff(float64(act), float64(exp)))
 }
 
 // DiffFloat compares the actual against the expected value and reports
-// an error if they differ by more than epsilon
-func DiffFloat[T constraints.Float](t *testing.T, id, name string,
+// an error if they differ by epsilon or more. A NaN is not equal to any
+// value, not even another NaN, and an infinity is only equal to an
+// infinity of the same sign. To check for an expected NaN use DiffFloatTol
+// with the NaNsEqual field of the FloatTol set.
+func DiffFloat[T constraints.Float](t testing.TB, id, name string,
 	act, exp, epsilon T,
 ) bool {
 	t.Helper()
 
 	if !almostEqual(act, exp, epsilon) {
-		t.Log(id)
-		reportFloatDiff(t, name, act, exp)
+		f := newValFailure(id, "DiffFloat", name, act, exp)
+		reportFloatDiff(f, name, act, exp)
+		f.report(t, "\t: %s is incorrect\n", name)
+
+		return true
+	}
+
+	return false
+}
+
+// DiffFloatTol compares the actual against the expected value and reports
+// an error if they differ by more than the tolerances allow. The
+// tolerances can be absolute, relative or in units in the last place (see
+// the FloatTol type for details). The units in the last place are measured
+// using the type of the values (float32 or float64).
+func DiffFloatTol[T constraints.Float](t testing.TB, id, name string,
+	act, exp T, ft FloatTol,
+) bool {
+	t.Helper()
+
+	if !tolEqual(act, exp, ft) {
+		f := newValFailure(id, "DiffFloatTol", name, act, exp)
+		f.logf("\t: tolerance: %s\n", ft)
+		reportFloatDiff(f, name, act, exp)
+		f.report(t, "\t: %s is incorrect\n", name)
 
 		return true
 	}
@@ -50,21 +97,52 @@ func DiffFloat[T constraints.Float](t *testing.T, id, name string,
 	return false
 }
 
+// DiffComplex compares the actual against the expected value and reports an
+// error if they differ by more than the tolerances allow. The real and
+// imaginary parts are compared separately using the tolerances (see the
+// FloatTol type for details).
+func DiffComplex[T constraints.Complex](t testing.TB, id, name string,
+	act, exp T, ft FloatTol,
+) bool {
+	t.Helper()
+
+	bits := reflect.TypeFor[T]().Bits() / 2 //nolint:mnd
+
+	if ft.equalBits(real(complex128(act)), real(complex128(exp)), bits) &&
+		ft.equalBits(imag(complex128(act)), imag(complex128(exp)), bits) {
+		return false
+	}
+
+	f := newValFailure(id, "DiffComplex", name, act, exp)
+
+	if !ft.IsZero() {
+		f.logf("\t: tolerance: %s\n", ft)
+	}
+
+	f.logf("\t: expected %s: %g\n", name, exp)
+	f.logf("\t:   actual %s: %g\n", name, act)
+	charCnt := len(name) + len("expected") + 1
+	f.logf("\t: %*s: %g\n", charCnt, "diff", act-exp)
+	f.report(t, "\t: %s is incorrect\n", name)
+
+	return true
+}
+
 // DiffInt compares the actual against the expected value and reports an
 // error if they differ
-func DiffInt[T constraints.Integer](t *testing.T, id, name string,
+func DiffInt[T constraints.Integer](t testing.TB, id, name string,
 	act, exp T,
 ) bool {
 	t.Helper()
 
 	if act != exp {
-		t.Log(id)
-		t.Logf("\t: expected %s: %5d\n", name, exp)
-		t.Logf("\t:   actual %s: %5d\n", name, act)
+		f := newValFailure(id, "DiffInt", name, act, exp)
+		f.logf("\t: expected %s: %5d\n", name, exp)
+		f.logf("\t:   actual %s: %5d\n", name, act)
 		charCnt := len(name) + len("expected") + 1
-		t.Logf("\t: %*s: %5d\n", charCnt, "diff",
+		f.logf("\t: %*s: %5d\n", charCnt, "diff",
 			int64(math.Abs(float64(act-exp))))
-		t.Errorf("\t: %s is incorrect\n", name)
+		f.report(t, "\t: %s is incorrect\n", name)
 
 		return true
 	}
@@ -91,24 +169,58 @@ func stringFirstDiff[S ~string](act, exp S) int {
 	return len(actRunes)
 }
 
-// reportStringDiff reports the difference between two strings
-func reportStringDiff[S ~string](t *testing.T, name string, act, exp S) {
-	t.Helper()
+// reportStringDiff adds the difference between two strings to the
+// Failure. If either string has more than one line the differences are
+// shown as a unified diff (see UnifiedDiff) with the given number of lines
+// of context.
+func reportStringDiff[S ~string](f *Failure, name string, act, exp S,
+	context int,
+) {
+	if isMultiLine(act, exp) {
+		f.logf("\t: %s differs (expected length: %d, actual length: %d)\n",
+			name, len(exp), len(act))
+		f.logf("\t: differences (-expected +actual):\n%s\n",
+			UnifiedDiff(string(act), string(exp), context))
+
+		return
+	}
 
-	t.Logf("\t: expected %s (length: %4d): %q\n", name, len(exp), exp)
-	t.Logf("\t:   actual %s (length: %4d): %q\n", name, len(act), act)
-	t.Logf("\t: first difference at rune %d\n", stringFirstDiff(act, exp))
-	t.Errorf("\t: %s is incorrect\n", name)
+	f.logf("\t: expected %s (length: %4d): %q\n", name, len(exp), exp)
+	f.logf("\t:   actual %s (length: %4d): %q\n", name, len(act), act)
+	f.logf("\t: first difference at rune %d\n", stringFirstDiff(act, exp))
 }
 
 // DiffString compares the actual against the expected value and reports an
-// error if they differ
-func DiffString[S ~string](t *testing.T, id, name string, act, exp S) bool {
+// error if they differ. The differences between multi-line strings are
+// shown with DiffContextLines lines of context.
+func DiffString[S ~string](t testing.TB, id, name string, act, exp S) bool {
+	t.Helper()
+
+	return diffString(t, id, "DiffString", name, act, exp, DiffContextLines)
+}
+
+// DiffStringContext compares the actual against the expected value in the
+// same way as DiffString but the differences between multi-line strings
+// are shown with the given number of lines of context.
+func DiffStringContext[S ~string](t testing.TB, id, name string,
+	act, exp S, context int,
+) bool {
+	t.Helper()
+
+	return diffString(t, id, "DiffStringContext", name, act, exp, context)
+}
+
+// diffString reports the difference between the strings, if any, and
+// returns true if they differ
+func diffString[S ~string](t testing.TB, id, kind, name string,
+	act, exp S, context int,
+) bool {
 	t.Helper()
 
 	if act != exp {
-		t.Log(id)
-		reportStringDiff(t, name, act, exp)
+		f := newValFailure(id, kind, name, act, exp)
+		reportStringDiff(f, name, act, exp, context)
+		f.report(t, "\t: %s is incorrect\n", name)
 
 		return true
 	}
@@ -120,7 +232,7 @@ func DiffString[S ~string](t *testing.T, id, name string, act, exp S) bool {
 // error if they differ. It will report them as different if one is nil or
 // has a nil value and the other isn't/doesn't or if they are both non-nil
 // and the string values differ.
-func DiffStringer(t *testing.T, id, name string, actS, expS fmt.Stringer) bool {
+func DiffStringer(t testing.TB, id, name string, actS, expS fmt.Stringer) bool {
 	t.Helper()
 
 	actIsNil := actS == nil || reflect.ValueOf(actS).IsNil()
@@ -131,19 +243,19 @@ func DiffStringer(t *testing.T, id, name string, actS, expS fmt.Stringer) bool {
 	}
 
 	if actIsNil {
-		t.Log(id)
-		t.Logf("\t: expected %s is non-nil\n", name)
-		t.Logf("\t:   actual %s is nil\n", name)
-		t.Errorf("\t: %s is incorrect\n", name)
+		f := newValFailure(id, "DiffStringer", name, nil, expS)
+		f.logf("\t: expected %s is non-nil\n", name)
+		f.logf("\t:   actual %s is nil\n", name)
+		f.report(t, "\t: %s is incorrect\n", name)
 
 		return true
 	}
 
 	if expIsNil {
-		t.Log(id)
-		t.Logf("\t: expected %s is nil\n", name)
-		t.Logf("\t:   actual %s is non-nil\n", name)
-		t.Errorf("\t: %s is incorrect\n", name)
+		f := newValFailure(id, "DiffStringer", name, actS, nil)
+		f.logf("\t: expected %s is nil\n", name)
+		f.logf("\t:   actual %s is non-nil\n", name)
+		f.report(t, "\t: %s is incorrect\n", name)
 
 		return true
 	}
@@ -153,14 +265,14 @@ func DiffStringer(t *testing.T, id, name string, actS, expS fmt.Stringer) bool {
 
 // DiffBool compares the actual against the expected value and reports an
 // error if they differ
-func DiffBool(t *testing.T, id, name string, act, exp bool) bool {
+func DiffBool(t testing.TB, id, name string, act, exp bool) bool {
 	t.Helper()
 
 	if act != exp {
-		t.Log(id)
-		t.Logf("\t: expected %s: %v\n", name, exp)
-		t.Logf("\t:   actual %s: %v\n", name, act)
-		t.Errorf("\t: %s is incorrect\n", name)
+		f := newValFailure(id, "DiffBool", name, act, exp)
+		f.logf("\t: expected %s: %v\n", name, exp)
+		f.logf("\t:   actual %s: %v\n", name, act)
+		f.report(t, "\t: %s is incorrect\n", name)
 
 		return true
 	}
@@ -169,27 +281,20 @@ func DiffBool(t *testing.T, id, name string, act, exp bool) bool {
 }
 
 // DiffTime compares the actual against the expected value and reports an
-// error if they differ
-func DiffTime(t *testing.T, id, name string, act, exp time.Time) bool {
+// error if they differ. Any monotonic clock readings are ignored and the
+// locations are not compared. Use the Diff method of DiffTimeCfg for more
+// control over the comparison.
+func DiffTime(t testing.TB, id, name string, act, exp time.Time) bool {
 	t.Helper()
 
-	if d := act.Sub(exp); d != 0 {
-		t.Log(id)
-		t.Logf("\t: expected %s: %v\n", name, exp)
-		t.Logf("\t:   actual %s: %v\n", name, act)
-		t.Logf("\t: difference: %v\n", d)
-		t.Errorf("\t: %s is incorrect\n", name)
-
-		return true
-	}
-
-	return false
+	return DiffTimeCfg{}.Diff(t, id, name, act, exp)
 }
 
 // DiffErr compares the actual against the expected value and reports an
 // error if they differ. Note that it compares the string representation and
-// not the error type so there might be a mismatch.
-func DiffErr(t *testing.T, id, name string, act, exp error) bool {
+// not the error type so there might be a mismatch. See the DiffErrIs,
+// DiffErrAs and DiffErrTree funcs for other ways of comparing errors.
+func DiffErr(t testing.TB, id, name string, act, exp error) bool {
 	t.Helper()
 
 	if act == nil && exp == nil {
@@ -199,10 +304,10 @@ func DiffErr(t *testing.T, id, name string, act, exp error) bool {
 	if act == nil && exp != nil ||
 		act != nil && exp == nil ||
 		act.Error() != exp.Error() {
-		t.Log(id)
-		t.Logf("\t: expected %s: %v\n", name, exp)
-		t.Logf("\t:   actual %s: %v\n", name, act)
-		t.Errorf("\t: %s is incorrect\n", name)
+		f := newValFailure(id, "DiffErr", name, act, exp)
+		f.logf("\t: expected %s: %v\n", name, exp)
+		f.logf("\t:   actual %s: %v\n", name, act)
+		f.report(t, "\t: %s is incorrect\n", name)
 
 		return true
 	}
@@ -215,146 +320,461 @@ func DiffErr(t *testing.T, id, name string, act, exp error) bool {
 // any remaining differences are suppressed.
 const MaxReportedDiffs = 5
 
-// reportDiffCount reports the number of differences found
-func reportDiffCount(t *testing.T, name string, diffCount int) {
+// reportDiffCount adds the number of differences found to the Failure and
+// reports it
+func reportDiffCount(t testing.TB, f *Failure, name string, diffCount int) {
 	t.Helper()
 
-	if diffCount > 0 {
-		diffStr := "differences"
-		if diffCount == 1 {
-			diffStr = "difference"
-		}
+	diffStr := "differences"
+	if diffCount == 1 {
+		diffStr = "difference"
+	}
+
+	f.logf("\t: %d %s found\n", diffCount, diffStr)
+	f.report(t, "\t: %s is incorrect\n", name)
+}
 
-		t.Logf("\t: %d %s found\n", diffCount, diffStr)
-		t.Errorf("\t: %s is incorrect\n", name)
+// reportMaxDiffsShown adds an elipsis to the Failure to show that more
+// differences have been found but that they have been elided. This is only
+// done for the first elided difference.
+func reportMaxDiffsShown(f *Failure, diffCount int) {
+	if diffCount == (MaxReportedDiffs + 1) {
+		f.log("\t: ...\n")
 	}
 }
 
-// reportMaxDiffsShown logs an elipsis to show that more differences have
-// been found but that they have been elided. This is only done for the first
-// elided difference.
-func reportMaxDiffsShown(t *testing.T, diffCount int) {
-	t.Helper()
+// reportSliceLens adds the lengths of the slices to the Failure if they
+// differ
+func reportSliceLens(f *Failure, name string, act, exp int) {
+	if act != exp {
+		f.logf("\t: expected %s length: %4d\n", name, exp)
+		f.logf("\t:   actual %s length: %4d\n", name, act)
+	}
+}
 
-	if diffCount == (MaxReportedDiffs + 1) {
-		t.Log("\t: ...\n")
+// sliceElemName returns the name of the changed slice element. The index
+// is given for both the actual and expected slices if they differ.
+func sliceElemName(name string, e SliceEdit) string {
+	if e.ActIdx == e.ExpIdx {
+		return fmt.Sprintf("%s [%d]", name, e.ExpIdx)
 	}
+
+	return fmt.Sprintf("%s [expected: %d, actual: %d]",
+		name, e.ExpIdx, e.ActIdx)
 }
 
-// reportSliceLenDiff reports if the lengths of the slices differ. It returns
-// true if so and false otherwise.
-func reportSliceLenDiff(t *testing.T, id, name string, act, exp int) bool {
+// reportSliceEdits reports the edits needed to transform the expected slice
+// into the actual slice. Elements present in only one of the slices are
+// reported as unexpected or missing and the reportChange func is called to
+// add elements which have changed to the Failure. At most MaxReportedDiffs
+// edits are reported. It returns true if there were any edits, false
+// otherwise.
+func reportSliceEdits[T any](t testing.TB, id, kind, name string,
+	act, exp []T, edits []SliceEdit,
+	reportChange func(f *Failure, e SliceEdit),
+) bool {
 	t.Helper()
 
-	if act != exp {
-		t.Log(id)
-		t.Logf("\t: expected %s length: %4d\n", name, exp)
-		t.Logf("\t:   actual %s length: %4d\n", name, act)
-		t.Errorf("\t: %s is incorrect\n", name)
+	if len(edits) == 0 {
+		return false
+	}
 
-		return true
+	f := newFailure(id, kind, name)
+	f.Act, f.Exp = PrettyPrint(act), PrettyPrint(exp)
+
+	reportSliceLens(f, name, len(act), len(exp))
+
+	for i, e := range edits {
+		diffCount := i + 1
+		if diffCount > MaxReportedDiffs {
+			reportMaxDiffsShown(f, diffCount)
+			break
+		}
+
+		switch e.Op {
+		case EditInsert:
+			f.logf("\t: %10s %s [%d]: %s\n",
+				"unexpected", name, e.ActIdx, PrettyPrint(act[e.ActIdx]))
+		case EditDelete:
+			f.logf("\t: %10s %s [%d]: %s\n",
+				"missing", name, e.ExpIdx, PrettyPrint(exp[e.ExpIdx]))
+		case EditChange:
+			reportChange(f, e)
+		}
 	}
 
-	return false
+	reportDiffCount(t, f, name, len(edits))
+
+	return true
 }
 
 // DiffSlice compares the actual against the expected value and reports
-// an error if they differ. At most MaxReportedDiffs are reported.
-func DiffSlice[C comparable](t *testing.T, id, name string, act, exp []C) bool {
+// an error if they differ. The differences are found by matching the
+// elements of the slices (see SliceEdits) so that an element inserted
+// into or deleted from the middle of a slice is reported as such. At most
+// MaxReportedDiffs are reported. A nil slice and an empty slice are taken
+// to be the same, use DiffSliceNilness if this matters.
+func DiffSlice[C comparable](t testing.TB, id, name string, act, exp []C) bool {
 	t.Helper()
 
-	if reportSliceLenDiff(t, id, name, len(act), len(exp)) {
-		return true
-	}
+	return reportSliceEdits(t, id, "DiffSlice", name, act, exp,
+		SliceEdits(act, exp),
+		func(f *Failure, e SliceEdit) {
+			f.logf("\t: expected %s [%d]: %s\n",
+				name, e.ExpIdx, PrettyPrint(exp[e.ExpIdx]))
+			f.logf("\t:   actual %s [%d]: %s\n",
+				name, e.ActIdx, PrettyPrint(act[e.ActIdx]))
+		})
+}
 
-	diffCount := 0
+// DiffSliceNilness reports an error if one of the slices is nil and the
+// other is not. The other slice helper funcs take nil and empty slices to
+// be the same (see NilEqualsEmpty); call this as well if the distinction
+// matters (see NilDiffersFromEmpty).
+func DiffSliceNilness[T any](t testing.TB, id, name string, act, exp []T,
+) bool {
+	t.Helper()
 
-	for i, v := range act {
-		if v != exp[i] {
-			diffCount++
-			if diffCount > MaxReportedDiffs {
-				reportMaxDiffsShown(t, diffCount)
-				continue
-			}
+	if (act == nil) == (exp == nil) {
+		return false
+	}
 
-			if diffCount == 1 {
-				t.Log(id)
-			}
+	f := newFailure(id, "DiffSliceNilness", name)
+	f.Act, f.Exp = PrettyPrint(act), PrettyPrint(exp)
 
-			t.Logf("\t: expected %s [%d]: %v\n", name, i, exp[i])
-			t.Logf("\t:   actual %s [%d]: %v\n", name, i, v)
-		}
+	if act == nil {
+		f.logf("\t: expected %s is non-nil\n", name)
+		f.logf("\t:   actual %s is nil\n", name)
+	} else {
+		f.logf("\t: expected %s is nil\n", name)
+		f.logf("\t:   actual %s is non-nil\n", name)
 	}
 
-	reportDiffCount(t, name, diffCount)
+	f.report(t, "\t: %s is incorrect\n", name)
 
-	return diffCount > 0
+	return true
 }
 
 // DiffFloatSlice compares the actual against the expected value and reports
-// an error if they differ. At most MaxReportedDiffs are reported.
-func DiffFloatSlice[F constraints.Float](t *testing.T, id, name string,
+// an error if they differ. The differences are found by matching the
+// elements of the slices (see SliceEditsFunc) so that an element inserted
+// into or deleted from the middle of a slice is reported as such. At most
+// MaxReportedDiffs are reported. A nil slice and an empty slice are taken
+// to be the same, use DiffSliceNilness if this matters.
+func DiffFloatSlice[F constraints.Float](t testing.TB, id, name string,
 	act, exp []F, epsilon F,
 ) bool {
 	t.Helper()
 
-	if reportSliceLenDiff(t, id, name, len(act), len(exp)) {
-		return true
-	}
+	edits := SliceEditsFunc(act, exp,
+		func(a, e F) bool { return almostEqual(a, e, epsilon) })
 
-	diffCount := 0
+	return reportSliceEdits(t, id, "DiffFloatSlice", name, act, exp, edits,
+		func(f *Failure, e SliceEdit) {
+			elemName := sliceElemName(name, e)
+			reportFloatDiff(f, elemName, act[e.ActIdx], exp[e.ExpIdx])
+			f.logf("\t: %s is incorrect\n", elemName)
+		})
+}
+
+// DiffFloatSliceTol compares the actual against the expected value and
+// reports an error if they differ in the same way as DiffFloatSlice but the
+// elements are compared using the tolerances (see the DiffFloatTol func).
+func DiffFloatSliceTol[F constraints.Float](t testing.TB, id, name string,
+	act, exp []F, ft FloatTol,
+) bool {
+	t.Helper()
+
+	edits := SliceEditsFunc(act, exp,
+		func(a, e F) bool { return tolEqual(a, e, ft) })
+
+	return reportSliceEdits(t, id, "DiffFloatSliceTol", name, act, exp, edits,
+		func(f *Failure, e SliceEdit) {
+			elemName := sliceElemName(name, e)
+			reportFloatDiff(f, elemName, act[e.ActIdx], exp[e.ExpIdx])
+			f.logf("\t: %s is incorrect\n", elemName)
+		})
+}
+
+// DiffStringSlice compares the actual against the expected value and reports
+// an error if they differ. The differences are found by matching the
+// elements of the slices (see SliceEdits) so that an element inserted
+// into or deleted from the middle of a slice is reported as such. At most
+// MaxReportedDiffs are reported. A nil slice and an empty slice are taken
+// to be the same, use DiffSliceNilness if this matters.
+func DiffStringSlice[S ~string](t testing.TB, id, name string,
+	act, exp []S,
+) bool {
+	t.Helper()
+
+	return reportSliceEdits(t, id, "DiffStringSlice", name, act, exp,
+		SliceEdits(act, exp),
+		func(f *Failure, e SliceEdit) {
+			elemName := sliceElemName(name, e)
+			reportStringDiff(f, elemName, act[e.ActIdx], exp[e.ExpIdx],
+				DiffContextLines)
+			f.logf("\t: %s is incorrect\n", elemName)
+		})
+}
+
+// multisetDiff compares the two slices as multisets, ignoring the order of
+// the elements. It returns the indexes of the elements in act which are not
+// in exp and the indexes of the elements in exp which are not in act.
+//
+// Values which are not equal to themselves (such as floating point NaN
+// values) cannot be counted using a map and so they are matched pairwise
+// using DiffVals with a FloatTol which takes two NaN values to be equal.
+func multisetDiff[C comparable](act, exp []C) (unexpected, missing []int) {
+	counts := make(map[C]int, len(exp))
+	unmatched := []int{} // the indexes of the unmatched values in exp != self
+	nanCmp := DiffValsCfg{FloatTol: FloatTol{NaNsEqual: true}}
+
+	for i, v := range exp {
+		if v != v { //nolint:gocritic
+			unmatched = append(unmatched, i)
+			continue
+		}
+
+		counts[v]++
+	}
 
 	for i, v := range act {
-		if !almostEqual(v, exp[i], epsilon) {
-			diffCount++
-			if diffCount > MaxReportedDiffs {
-				reportMaxDiffsShown(t, diffCount)
+		if v != v { //nolint:gocritic
+			j := slices.IndexFunc(unmatched, func(j int) bool {
+				return nanCmp.DiffVals(v, exp[j]) == nil
+			})
+			if j >= 0 {
+				unmatched = slices.Delete(unmatched, j, j+1)
 				continue
 			}
+		} else if counts[v] > 0 {
+			counts[v]--
+			continue
+		}
+
+		unexpected = append(unexpected, i)
+	}
 
-			if diffCount == 1 {
-				t.Log(id)
+	for i, v := range exp {
+		if v != v { //nolint:gocritic
+			if slices.Contains(unmatched, i) {
+				missing = append(missing, i)
 			}
 
-			reportFloatDiff(t, fmt.Sprintf("%s [%d]", name, i), v, exp[i])
+			continue
 		}
-	}
 
-	reportDiffCount(t, name, diffCount)
+		if counts[v] > 0 {
+			counts[v]--
+
+			missing = append(missing, i)
+		}
+	}
 
-	return diffCount > 0
+	return unexpected, missing
 }
 
-// DiffStringSlice compares the actual against the expected value and reports
-// an error if they differ. At most MaxReportedDiffs are reported.
-func DiffStringSlice[S ~string](t *testing.T, id, name string,
-	act, exp []S,
+// DiffSliceUnordered compares the actual against the expected value and
+// reports an error if they differ. The order of the elements is ignored,
+// the slices are treated as multisets and so they are taken to be the same
+// if each element appears the same number of times in each. Any elements
+// in the actual slice but not in the expected slice are reported as
+// unexpected and any in the expected but not the actual are reported as
+// missing. At most MaxReportedDiffs are reported. Two floating point NaN
+// values are taken to be equal so that a slice holding NaN values is the
+// same as itself. A nil slice and an empty slice are taken to be the same,
+// use DiffSliceNilness if this matters.
+func DiffSliceUnordered[C comparable](t testing.TB, id, name string,
+	act, exp []C,
 ) bool {
 	t.Helper()
 
-	if reportSliceLenDiff(t, id, name, len(act), len(exp)) {
-		return true
+	unexpected, missing := multisetDiff(act, exp)
+	if len(unexpected) == 0 && len(missing) == 0 {
+		return false
 	}
 
+	f := newFailure(id, "DiffSliceUnordered", name)
+	f.Act, f.Exp = PrettyPrint(act), PrettyPrint(exp)
 	diffCount := 0
 
-	for i, s := range act {
-		if s != exp[i] {
+	for _, diff := range []struct {
+		desc string
+		idxs []int
+		vals []C
+	}{
+		{desc: "unexpected", idxs: unexpected, vals: act},
+		{desc: "missing", idxs: missing, vals: exp},
+	} {
+		for _, i := range diff.idxs {
 			diffCount++
 			if diffCount > MaxReportedDiffs {
-				reportMaxDiffsShown(t, diffCount)
+				reportMaxDiffsShown(f, diffCount)
 				continue
 			}
 
-			if diffCount == 1 {
-				t.Log(id)
-			}
+			f.logf("\t: %10s %s [%d]: %s\n",
+				diff.desc, name, i, PrettyPrint(diff.vals[i]))
+		}
+	}
+
+	reportDiffCount(t, f, name, diffCount)
+
+	return true
+}
+
+// mapEntry holds a key together with the values for that key in the actual
+// and expected maps. The inAct and inExp flags record which maps have an
+// entry with the key.
+type mapEntry[K comparable, V any] struct {
+	key          K
+	actV, expV   V
+	inAct, inExp bool
+}
+
+// mapEntries returns the entries of both maps, pairing those with equal
+// keys, in a deterministic order (see the compareKeys func). The maps are
+// iterated over rather than indexed by key so that entries whose keys
+// cannot be looked up (such as NaN keys) are still found. Entries with keys
+// which compare as the same are ordered with the actual entries first and
+// then by value.
+func mapEntries[K comparable, V any](act, exp map[K]V) []mapEntry[K, V] {
+	entries := make([]mapEntry[K, V], 0, max(len(act), len(exp)))
+
+	for k, v := range act {
+		e := mapEntry[K, V]{key: k, actV: v, inAct: true}
+		e.expV, e.inExp = exp[k]
+		entries = append(entries, e)
+	}
+
+	for k, v := range exp {
+		if _, ok := act[k]; !ok {
+			entries = append(entries,
+				mapEntry[K, V]{key: k, expV: v, inExp: true})
+		}
+	}
+
+	slices.SortFunc(entries, func(a, b mapEntry[K, V]) int {
+		return cmp.Or(
+			compareVals(a.key, b.key),
+			cmp.Compare(boolOrder(b.inAct), boolOrder(a.inAct)),
+			compareVals(a.actV, b.actV),
+			compareVals(a.expV, b.expV))
+	})
+
+	return entries
+}
+
+// compareVals compares the values in the same way as the compareKeys func
+func compareVals[T any](a, b T) int {
+	return compareKeys(reflect.ValueOf(&a).Elem(), reflect.ValueOf(&b).Elem())
+}
+
+// mapEntryName returns the name of the map entry with the given key
+func mapEntryName[K comparable](name string, k K) string {
+	return fmt.Sprintf("%s [%s]", name, PrettyPrint(k))
+}
+
+// reportMapDiffs compares the maps and reports the differences. Entries
+// present in only one of the maps are reported as unexpected or missing
+// and the reportChange func is called to report entries whose values
+// differ (as decided by the eq func). The entries are reported in key
+// order and at most MaxReportedDiffs are reported. It returns true if there
+// were any differences, false otherwise.
+func reportMapDiffs[K comparable, V any](t testing.TB, id, kind, name string,
+	act, exp map[K]V, eq func(a, e V) bool,
+	reportChange func(f *Failure, k K, actV, expV V),
+) bool {
+	t.Helper()
+
+	f := newFailure(id, kind, name)
+	f.Act, f.Exp = PrettyPrint(act), PrettyPrint(exp)
+	diffCount := 0
+
+	for _, e := range mapEntries(act, exp) {
+		if e.inAct && e.inExp && eq(e.actV, e.expV) {
+			continue
+		}
 
-			reportStringDiff(t, fmt.Sprintf("%s [%d]", name, i), s, exp[i])
+		diffCount++
+		if diffCount > MaxReportedDiffs {
+			reportMaxDiffsShown(f, diffCount)
+			continue
 		}
+
+		if diffCount == 1 {
+			reportMapLens(f, name, len(act), len(exp))
+		}
+
+		switch {
+		case !e.inExp:
+			f.logf("\t: %10s %s: %s\n",
+				"unexpected", mapEntryName(name, e.key), PrettyPrint(e.actV))
+		case !e.inAct:
+			f.logf("\t: %10s %s: %s\n",
+				"missing", mapEntryName(name, e.key), PrettyPrint(e.expV))
+		default:
+			reportChange(f, e.key, e.actV, e.expV)
+		}
+	}
+
+	if diffCount == 0 {
+		return false
+	}
+
+	reportDiffCount(t, f, name, diffCount)
+
+	return true
+}
+
+// reportMapLens adds the lengths of the maps to the Failure if they differ
+func reportMapLens(f *Failure, name string, act, exp int) {
+	if act != exp {
+		f.logf("\t: expected %s entries: %4d\n", name, exp)
+		f.logf("\t:   actual %s entries: %4d\n", name, act)
 	}
+}
 
-	reportDiffCount(t, name, diffCount)
+// DiffMap compares the actual against the expected value and reports an
+// error if they differ. Entries in the actual map but not in the expected
+// map are reported as unexpected, those in the expected map but not the
+// actual are reported as missing and any entries with differing values are
+// also reported. The entries are reported in a deterministic key order
+// (the same order as used by the ValPrinter) and at most MaxReportedDiffs
+// are reported. A nil map and an empty map are taken to
+// be the same.
+//
+// Note that, as a NaN key is not equal to any other key (even another NaN),
+// any entries with NaN keys will be reported as unexpected (if they are in
+// the actual map) or missing (if they are in the expected map).
+func DiffMap[K, V comparable](t testing.TB, id, name string,
+	act, exp map[K]V,
+) bool {
+	t.Helper()
+
+	return reportMapDiffs(t, id, "DiffMap", name, act, exp,
+		func(a, e V) bool { return a == e },
+		func(f *Failure, k K, actV, expV V) {
+			f.logf("\t: expected %s: %s\n",
+				mapEntryName(name, k), PrettyPrint(expV))
+			f.logf("\t:   actual %s: %s\n",
+				mapEntryName(name, k), PrettyPrint(actV))
+		})
+}
+
+// DiffFloatMap compares the actual against the expected value and reports
+// an error if they differ in the same way as DiffMap but the values are
+// taken to be the same if they differ by less than epsilon.
+func DiffFloatMap[K comparable, F constraints.Float](t testing.TB,
+	id, name string, act, exp map[K]F, epsilon F,
+) bool {
+	t.Helper()
 
-	return diffCount > 0
+	return reportMapDiffs(t, id, "DiffFloatMap", name, act, exp,
+		func(a, e F) bool { return almostEqual(a, e, epsilon) },
+		func(f *Failure, k K, actV, expV F) {
+			entryName := mapEntryName(name, k)
+			reportFloatDiff(f, entryName, actV, expV)
+			f.logf("\t: %s is incorrect\n", entryName)
+		})
 }
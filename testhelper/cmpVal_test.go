@@ -1,6 +1,7 @@
 package testhelper
 
 import (
+	"math"
 	"testing"
 )
 
@@ -48,3 +49,249 @@ func TestStringFirstDiff(t *testing.T) {
 		DiffInt(t, tc.IDStr(), "firstDiff", fd, tc.expFirstDiff)
 	}
 }
+
+func TestMultisetDiff(t *testing.T) {
+	testCases := []struct {
+		ID
+		act           []string
+		exp           []string
+		expUnexpected []int
+		expMissing    []int
+	}{
+		{
+			ID: MkID("both empty"),
+		},
+		{
+			ID:  MkID("same order"),
+			act: []string{"a", "b", "c"},
+			exp: []string{"a", "b", "c"},
+		},
+		{
+			ID:  MkID("different order"),
+			act: []string{"c", "a", "b"},
+			exp: []string{"a", "b", "c"},
+		},
+		{
+			ID:            MkID("unexpected"),
+			act:           []string{"c", "x", "a", "b"},
+			exp:           []string{"a", "b", "c"},
+			expUnexpected: []int{1},
+		},
+		{
+			ID:         MkID("missing"),
+			act:        []string{"c", "b"},
+			exp:        []string{"a", "b", "c"},
+			expMissing: []int{0},
+		},
+		{
+			ID:            MkID("repeated values"),
+			act:           []string{"a", "a", "a", "b"},
+			exp:           []string{"b", "a", "b", "a"},
+			expUnexpected: []int{2},
+			expMissing:    []int{0},
+		},
+	}
+
+	for _, tc := range testCases {
+		unexpected, missing := multisetDiff(tc.act, tc.exp)
+		DiffSlice(t, tc.IDStr(), "unexpected", unexpected, tc.expUnexpected)
+		DiffSlice(t, tc.IDStr(), "missing", missing, tc.expMissing)
+	}
+}
+
+func TestMultisetDiffNaN(t *testing.T) {
+	nan := math.NaN()
+
+	testCases := []struct {
+		ID
+		act           []float64
+		exp           []float64
+		expUnexpected []int
+		expMissing    []int
+	}{
+		{
+			ID:  MkID("NaN in both"),
+			act: []float64{nan},
+			exp: []float64{nan},
+		},
+		{
+			ID:  MkID("NaNs in both, different order"),
+			act: []float64{nan, 1, nan},
+			exp: []float64{1, nan, nan},
+		},
+		{
+			ID:         MkID("NaN missing"),
+			act:        []float64{},
+			exp:        []float64{nan},
+			expMissing: []int{0},
+		},
+		{
+			ID:            MkID("NaN unexpected"),
+			act:           []float64{1, nan},
+			exp:           []float64{1},
+			expUnexpected: []int{1},
+		},
+		{
+			ID:            MkID("more NaNs in actual"),
+			act:           []float64{nan, nan, 2},
+			exp:           []float64{2, nan},
+			expUnexpected: []int{1},
+		},
+		{
+			ID:         MkID("more NaNs in expected"),
+			act:        []float64{nan, 2},
+			exp:        []float64{nan, 2, nan},
+			expMissing: []int{2},
+		},
+	}
+
+	for _, tc := range testCases {
+		unexpected, missing := multisetDiff(tc.act, tc.exp)
+		DiffSlice(t, tc.IDStr(), "unexpected", unexpected, tc.expUnexpected)
+		DiffSlice(t, tc.IDStr(), "missing", missing, tc.expMissing)
+	}
+
+	if DiffSliceUnordered(t, "test: DiffSliceUnordered", "NaNs",
+		[]float64{nan, 1}, []float64{1, nan}) {
+		t.Error("\t: DiffSliceUnordered should have found no differences")
+	}
+}
+
+func TestDiffMap(t *testing.T) {
+	nan := math.NaN()
+
+	testCases := []struct {
+		ID
+		f      func(t testing.TB) bool
+		expLog string
+	}{
+		{
+			ID: MkID("DiffMap, same"),
+			f: func(t testing.TB) bool {
+				return DiffMap(t, "id", "m",
+					map[string]int{"a": 1, "b": 2},
+					map[string]int{"b": 2, "a": 1})
+			},
+		},
+		{
+			ID: MkID("DiffMap, nil and empty"),
+			f: func(t testing.TB) bool {
+				return DiffMap(t, "id", "m", nil, map[string]int{})
+			},
+		},
+		{
+			ID: MkID("DiffMap"),
+			f: func(t testing.TB) bool {
+				return DiffMap(t, "id", "m",
+					map[string]int{"d": 4, "a": 1, "b": 2},
+					map[string]int{"c": 3, "b": 3, "a": 1})
+			},
+			expLog: "id\n" +
+				"\t: expected m [\"b\"]: 3\n" +
+				"\t:   actual m [\"b\"]: 2\n" +
+				"\t:    missing m [\"c\"]: 3\n" +
+				"\t: unexpected m [\"d\"]: 4\n" +
+				"\t: 3 differences found\n" +
+				"\t: m is incorrect\n",
+		},
+		{
+			ID: MkID("DiffMap, lengths differ"),
+			f: func(t testing.TB) bool {
+				return DiffMap(t, "id", "m",
+					map[int]string{1: "a"},
+					map[int]string{1: "b", 2: "c"})
+			},
+			expLog: "id\n" +
+				"\t: expected m entries:    2\n" +
+				"\t:   actual m entries:    1\n" +
+				"\t: expected m [1]: \"b\"\n" +
+				"\t:   actual m [1]: \"a\"\n" +
+				"\t:    missing m [2]: \"c\"\n" +
+				"\t: 2 differences found\n" +
+				"\t: m is incorrect\n",
+		},
+		{
+			ID: MkID("DiffMap, too many differences"),
+			f: func(t testing.TB) bool {
+				return DiffMap(t, "id", "m",
+					map[int]int{1: 1, 2: 2, 3: 3, 4: 4, 5: 5, 6: 6, 7: 7},
+					map[int]int{1: 0, 2: 0, 3: 0, 4: 0, 5: 0, 6: 0, 7: 0})
+			},
+			expLog: "id\n" +
+				"\t: expected m [1]: 0\n" +
+				"\t:   actual m [1]: 1\n" +
+				"\t: expected m [2]: 0\n" +
+				"\t:   actual m [2]: 2\n" +
+				"\t: expected m [3]: 0\n" +
+				"\t:   actual m [3]: 3\n" +
+				"\t: expected m [4]: 0\n" +
+				"\t:   actual m [4]: 4\n" +
+				"\t: expected m [5]: 0\n" +
+				"\t:   actual m [5]: 5\n" +
+				"\t: ...\n\n" +
+				"\t: 7 differences found\n" +
+				"\t: m is incorrect\n",
+		},
+		{
+			ID: MkID("DiffFloatMap"),
+			f: func(t testing.TB) bool {
+				return DiffFloatMap(t, "id", "m",
+					map[string]float64{"a": 1, "b": 2.5, "d": 4},
+					map[string]float64{"a": 1.0001, "b": 2, "c": 3},
+					0.001)
+			},
+			expLog: "id\n" +
+				"\t: expected m [\"b\"]:     2\n" +
+				"\t:   actual m [\"b\"]:   2.5\n" +
+				"\t:             diff:   0.5\n" +
+				"\t:         rel diff:   0.2\n" +
+				"\t: m [\"b\"] is incorrect\n" +
+				"\t:    missing m [\"c\"]: 3\n" +
+				"\t: unexpected m [\"d\"]: 4\n" +
+				"\t: 3 differences found\n" +
+				"\t: m is incorrect\n",
+		},
+		{
+			ID: MkID("DiffMap, NaN keys"),
+			f: func(t testing.TB) bool {
+				return DiffMap(t, "id", "m",
+					map[float64]int{nan: 1, 2: 2},
+					map[float64]int{nan: 3, 2: 2})
+			},
+			expLog: "id\n" +
+				"\t: unexpected m [math.NaN()]: 1\n" +
+				"\t:    missing m [math.NaN()]: 3\n" +
+				"\t: 2 differences found\n" +
+				"\t: m is incorrect\n",
+		},
+		{
+			ID: MkID("DiffFloatMap, same"),
+			f: func(t testing.TB) bool {
+				return DiffFloatMap(t, "id", "m",
+					map[int]float64{1: 1.0, 2: 2.0001},
+					map[int]float64{1: 1, 2: 2},
+					0.001)
+			},
+		},
+		{
+			ID: MkID("DiffFloatMap, NaN key"),
+			f: func(t testing.TB) bool {
+				return DiffFloatMap(t, "id", "m",
+					map[float64]float64{1: 1},
+					map[float64]float64{nan: 2, 1: 1},
+					0.001)
+			},
+			expLog: "id\n" +
+				"\t: expected m entries:    2\n" +
+				"\t:   actual m entries:    1\n" +
+				"\t:    missing m [math.NaN()]: 2\n" +
+				"\t: 1 difference found\n" +
+				"\t: m is incorrect\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		ft := &FakeTB{TB: t}
+		ft.CheckResult(t, tc.IDStr(), tc.f(ft), tc.expLog)
+	}
+}
@@ -0,0 +1,199 @@
+package testhelper
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// maxCellWidth is the maximum width of a cell in the table of failures
+// shown by a Collector. Longer values are truncated.
+const maxCellWidth = 30
+
+// Collector is a testing.TB which collects the failures found by the check
+// funcs rather than reporting them as they are found. When the Done method
+// is called (or when the test completes, if Done has not been called) the
+// failures are shown in a single table, one row per failure, giving the
+// ID passed to the check func, the kind of check, the name of the value and
+// the expected and actual values and the test is marked as failed. Use the
+// NewCollector func to create one and then pass it to the check funcs in
+// place of the testing.TB.
+//
+// For instance,
+//
+//	c := testhelper.NewCollector(t, tc)
+//	testhelper.DiffInt(c, tc.IDStr(), "count", count, tc.expCount)
+//	testhelper.DiffString(c, tc.IDStr(), "name", name, tc.expName)
+//	c.Done()
+//
+// If ShowDetails is set then the full details of each failure are shown
+// after the table.
+//
+// Each failure is also passed to the Reporter which the testing.TB was
+// using when the Collector was created so that, for instance, a
+// JSONReporter will still record it. Anything that Reporter logs is
+// discarded as the table is shown instead.
+type Collector struct {
+	testing.TB
+
+	ShowDetails bool
+
+	id       string
+	next     Reporter
+	mu       sync.Mutex
+	failures []Failure
+}
+
+// NewCollector returns a Collector for the test case which is bound to the
+// testing.TB. The Collector is used as the Reporter for itself (see the
+// SetTestReporter func) and the Done method is called when the test
+// completes.
+func NewCollector(t testing.TB, tc TestCase) *Collector {
+	t.Helper()
+
+	c := &Collector{TB: t, id: tc.IDStr(), next: reporterFor(t)}
+
+	t.Cleanup(c.Done)
+	SetTestReporter(c, c)
+
+	return c
+}
+
+// Report records the Failure to be shown by the Done method
+func (c *Collector) Report(_ testing.TB, f Failure) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.failures = append(c.failures, f)
+}
+
+// Failed reports whether any failures have been collected or the
+// underlying test has failed
+func (c *Collector) Failed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.failures) > 0 || c.TB.Failed()
+}
+
+// Done shows any failures collected since it was last called, passes them
+// to the Reporter the testing.TB was using when the Collector was created
+// and marks the test as failed if there were any. It does nothing if there
+// are no failures.
+func (c *Collector) Done() {
+	c.TB.Helper()
+
+	c.mu.Lock()
+	failures := c.failures
+	c.failures = nil
+	c.mu.Unlock()
+
+	if len(failures) == 0 {
+		return
+	}
+
+	for _, f := range failures {
+		c.next.Report(quietTB{TB: c.TB}, f)
+	}
+
+	rows := [][]string{
+		{"#", "id", "check", "name", "expected", "actual", "problem"},
+	}
+	for i, f := range failures {
+		rows = append(rows, []string{
+			strconv.Itoa(i + 1),
+			tableCell(f.ID),
+			f.Kind,
+			tableCell(f.Name),
+			tableCell(f.Exp),
+			tableCell(f.Act),
+			tableCell(plainMsg(f.Msg)),
+		})
+	}
+
+	c.TB.Log(c.id)
+
+	for _, line := range tableLines(rows) {
+		c.TB.Logf("\t: %s\n", line)
+	}
+
+	if c.ShowDetails {
+		for i, f := range failures {
+			c.TB.Logf("\t: details of failure #%d:\n", i+1)
+
+			for _, d := range f.Details {
+				c.TB.Logf("%s", d)
+			}
+
+			for _, n := range f.Notes {
+				c.TB.Logf("%s", n)
+			}
+		}
+	}
+
+	checkStr := "checks"
+	if len(failures) == 1 {
+		checkStr = "check"
+	}
+
+	c.TB.Errorf("\t: %d %s failed\n", len(failures), checkStr)
+}
+
+// quietTB is a testing.TB which discards anything logged and any errors
+// reported. It is used to pass the collected failures on to the next
+// Reporter without showing them twice.
+type quietTB struct {
+	testing.TB
+}
+
+func (quietTB) Log(...any)            {}
+func (quietTB) Logf(string, ...any)   {}
+func (quietTB) Error(...any)          {}
+func (quietTB) Errorf(string, ...any) {}
+
+// tableCell returns the value with any newlines shown as `\n` and
+// truncated if it is longer than maxCellWidth runes
+func tableCell(s string) string {
+	s = strings.ReplaceAll(s, "\n", `\n`)
+
+	if r := []rune(s); len(r) > maxCellWidth {
+		return string(r[:maxCellWidth-3]) + "..."
+	}
+
+	return s
+}
+
+// tableLines returns the rows formatted as lines with the columns aligned
+func tableLines(rows [][]string) []string {
+	widths := []int{}
+
+	for _, row := range rows {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+
+			widths[i] = max(widths[i], len([]rune(cell)))
+		}
+	}
+
+	lines := make([]string, 0, len(rows))
+
+	for _, row := range rows {
+		var line strings.Builder
+
+		for i, cell := range row {
+			if i > 0 {
+				line.WriteString(" | ")
+			}
+
+			fmt.Fprintf(&line, "%-*s", widths[i], cell)
+		}
+
+		lines = append(lines, strings.TrimRight(line.String(), " "))
+	}
+
+	return lines
+}
@@ -0,0 +1,155 @@
+package testhelper_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestCollector(t *testing.T) {
+	tcID := testhelper.MkID("collector")
+	ft := &fakeTB{TB: t}
+	c := testhelper.NewCollector(ft, tcID)
+
+	testhelper.DiffInt(c, "count check", "count", 1, 2)
+	testhelper.DiffBool(c, "flag check", "flag", true, true)
+	testhelper.DiffString(c, "name check", "name",
+		"a long string which will be truncated in the table", "short")
+	testhelper.CheckError(c, tcID.IDStr(), nil, true, nil)
+
+	if ft.Failed() {
+		t.Error("the failures should not be reported until Done is called")
+	}
+
+	if !c.Failed() {
+		t.Error("the Collector should report that it has failed")
+	}
+
+	c.Done()
+
+	if !ft.Failed() {
+		t.Error("the failures should have been reported by Done")
+	}
+
+	expLog := tcID.IDStr() + "\n" +
+		"\t: # | id                             | check      | name " +
+		" | expected | actual                         | problem\n" +
+		"\t: 1 | count check                    | DiffInt    | count" +
+		" | 2        | 1                              | count is incorrect\n" +
+		"\t: 2 | name check                     | DiffString | name " +
+		" | \"short\"  | \"a long string which will b... |" +
+		" name is incorrect\n" +
+		"\t: 3 | test: collector_test.go:13:... | CheckError | error" +
+		" |          |                                |" +
+		" an error was expected but n...\n" +
+		"\t: 3 checks failed\n"
+	testhelper.DiffString(t, "test: collector", "log", ft.Logged(), expLog)
+
+	ft.Logs = nil
+	c.Done()
+
+	if len(ft.Logs) != 0 {
+		t.Error("a second call of Done should show nothing, it showed:\n" +
+			strings.Join(ft.Logs, ""))
+	}
+}
+
+func TestCollectorDetails(t *testing.T) {
+	tcID := testhelper.MkID("collector with details")
+	ft := &fakeTB{TB: t}
+	c := testhelper.NewCollector(ft, tcID)
+	c.ShowDetails = true
+
+	testhelper.DiffInt(c, tcID.IDStr(), "count", 1, 2)
+	c.Done()
+
+	testhelper.ShouldContain(t, tcID.IDStr(), "log", ft.Logged(),
+		[]string{
+			"\t: details of failure #1:\n",
+			"\t: expected count:     2\n",
+			"\t: 1 check failed\n",
+		})
+}
+
+func TestCollectorNoFailures(t *testing.T) {
+	tcID := testhelper.MkID("collector without failures")
+	ft := &fakeTB{TB: t}
+	c := testhelper.NewCollector(ft, tcID)
+
+	testhelper.DiffInt(c, tcID.IDStr(), "count", 1, 1)
+	c.Done()
+
+	if ft.Failed() || len(ft.Logs) != 0 {
+		t.Error("nothing should have been reported, the log was:\n" +
+			ft.Logged())
+	}
+}
+
+func TestCollectorJSON(t *testing.T) {
+	tcID := testhelper.MkID("collector with a JSONReporter")
+	ft := &fakeTB{TB: t}
+
+	var buf bytes.Buffer
+
+	testhelper.SetTestReporter(ft, testhelper.NewJSONReporter(&buf, nil))
+
+	c := testhelper.NewCollector(ft, tcID)
+
+	testhelper.DiffInt(c, tcID.IDStr(), "count", 1, 2)
+	testhelper.DiffString(c, tcID.IDStr(), "name", "a", "b")
+
+	if buf.Len() != 0 {
+		t.Error("no records should be written until Done is called")
+	}
+
+	c.Done()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 JSON records, got %d:\n%s",
+			len(lines), buf.String())
+	}
+
+	for i, exp := range []testhelper.FailureRecord{
+		{
+			Test:     t.Name(),
+			ID:       tcID.IDStr(),
+			Kind:     "DiffInt",
+			Name:     "count",
+			Actual:   "1",
+			Expected: "2",
+			Msg:      "count is incorrect",
+		},
+		{
+			Test:     t.Name(),
+			ID:       tcID.IDStr(),
+			Kind:     "DiffString",
+			Name:     "name",
+			Actual:   `"a"`,
+			Expected: `"b"`,
+			Msg:      "name is incorrect",
+		},
+	} {
+		var rec testhelper.FailureRecord
+		if err := json.Unmarshal([]byte(lines[i]), &rec); err != nil {
+			t.Fatal("bad JSON record:", err)
+		}
+
+		testhelper.CheckVals(t, tcID, "record", rec, exp)
+	}
+
+	if !ft.Failed() {
+		t.Error("the failures should have been reported by Done")
+	}
+
+	testhelper.ShouldContain(t, tcID.IDStr(), "log", ft.Logged(),
+		[]string{"\t: 2 checks failed\n"})
+
+	if strings.Contains(ft.Logged(), "expected count:") {
+		t.Error("the failure details should not be shown, the log was:\n" +
+			ft.Logged())
+	}
+}
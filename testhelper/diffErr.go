@@ -0,0 +1,157 @@
+package testhelper
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// DiffErrIs reports an error if the actual error does not match the target
+// error as determined by errors.Is. This is useful for checking that an
+// error wraps a sentinel error value. If the target is nil then the actual
+// error is expected to be nil. The tree of errors wrapped by the actual
+// error is shown if it doesn't match.
+func DiffErrIs(t testing.TB, id, name string, act, target error) bool {
+	t.Helper()
+
+	if errors.Is(act, target) {
+		return false
+	}
+
+	f := newValFailure(id, "DiffErrIs", name, act, target)
+	f.logf("\t: expected %s to match (using errors.Is): %s\n",
+		name, errNodeStr(target))
+	f.logf("\t:   actual %s:\n", name)
+
+	for _, line := range errTreeLines(act) {
+		f.logf("\t:\t%s\n", line)
+	}
+
+	f.report(t, "\t: %s is incorrect\n", name)
+
+	return true
+}
+
+// DiffErrAs reports an error if the actual error does not match the type E
+// as determined by errors.As. This is useful for checking that an error
+// wraps an error of a particular type. The tree of errors wrapped by the
+// actual error is shown if it doesn't match.
+func DiffErrAs[E error](t testing.TB, id, name string, act error) bool {
+	t.Helper()
+
+	var target E
+	if errors.As(act, &target) {
+		return false
+	}
+
+	f := newStrFailure(id, "DiffErrAs", name,
+		errNodeStr(act), reflect.TypeFor[E]().String())
+	f.logf("\t: expected %s to match (using errors.As): %s\n",
+		name, reflect.TypeFor[E]())
+	f.logf("\t:   actual %s:\n", name)
+
+	for _, line := range errTreeLines(act) {
+		f.logf("\t:\t%s\n", line)
+	}
+
+	f.report(t, "\t: %s is incorrect\n", name)
+
+	return true
+}
+
+// DiffErrTree compares the actual against the expected error and reports an
+// error if they differ. Unlike DiffErr it compares the whole tree of errors
+// found by unwrapping them (following both the Unwrap() error and the
+// Unwrap() []error methods, as used by errors.Join). Each error in the tree
+// must have the same type and the same message as the corresponding error
+// in the expected tree. If the trees differ they are shown side by side
+// with the differing lines marked.
+func DiffErrTree(t testing.TB, id, name string, act, exp error) bool {
+	t.Helper()
+
+	actLines := errTreeLines(act)
+	expLines := errTreeLines(exp)
+
+	if slices.Equal(actLines, expLines) {
+		return false
+	}
+
+	width := len("expected")
+	for _, line := range expLines {
+		width = max(width, len(line))
+	}
+
+	f := newValFailure(id, "DiffErrTree", name, act, exp)
+	f.logf("\t: %s error trees differ\n", name)
+	f.logf("\t:   %-*s | %s\n", width, "expected", "actual")
+
+	for i := range max(len(actLines), len(expLines)) {
+		var actLine, expLine string
+		if i < len(actLines) {
+			actLine = actLines[i]
+		}
+
+		if i < len(expLines) {
+			expLine = expLines[i]
+		}
+
+		marker := " "
+		if actLine != expLine {
+			marker = "*"
+		}
+
+		f.logf("\t: %s %-*s | %s\n", marker, width, expLine, actLine)
+	}
+
+	f.report(t, "\t: %s is incorrect\n", name)
+
+	return true
+}
+
+// errNodeStr returns a string describing the error, giving its type and
+// its message
+func errNodeStr(err error) string {
+	if err == nil {
+		return "nil"
+	}
+
+	return fmt.Sprintf("%T: %q", err, err.Error())
+}
+
+// errTreeLines returns the tree of errors found by unwrapping the error,
+// one line per error, indented to show the structure of the tree
+func errTreeLines(err error) []string {
+	lines := []string{}
+
+	var addLines func(err error, depth int)
+
+	addLines = func(err error, depth int) {
+		lines = append(lines, strings.Repeat("  ", depth)+errNodeStr(err))
+
+		for _, e := range unwrapAll(err) {
+			addLines(e, depth+1)
+		}
+	}
+
+	addLines(err, 0)
+
+	return lines
+}
+
+// unwrapAll returns the errors wrapped by the error. It will return nil if
+// the error wraps no other errors.
+func unwrapAll(err error) []error {
+	switch e := err.(type) { //nolint:errorlint
+	case interface{ Unwrap() error }:
+		if wrapped := e.Unwrap(); wrapped != nil {
+			return []error{wrapped}
+		}
+	case interface{ Unwrap() []error }:
+		return e.Unwrap()
+	}
+
+	return nil
+}
@@ -0,0 +1,205 @@
+package testhelper
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+)
+
+func TestDiffErr(t *testing.T) {
+	errSentinel := errors.New("s")
+	pathErr := &fs.PathError{Op: "op", Path: "p", Err: errors.New("e")}
+	wrapped := fmt.Errorf("wrapped: %w", pathErr)
+	mkTree := func() error {
+		return fmt.Errorf("outer: %w",
+			errors.Join(errors.New("a"), fmt.Errorf("b: %w", fs.ErrNotExist)))
+	}
+
+	testCases := []struct {
+		ID
+		f      func(t testing.TB) bool
+		expLog string
+	}{
+		{
+			ID: MkID("DiffErrIs, both nil"),
+			f: func(t testing.TB) bool {
+				return DiffErrIs(t, "id", "err", nil, nil)
+			},
+		},
+		{
+			ID: MkID("DiffErrIs, identical"),
+			f: func(t testing.TB) bool {
+				return DiffErrIs(t, "id", "err", errSentinel, errSentinel)
+			},
+		},
+		{
+			ID: MkID("DiffErrIs, wrapped"),
+			f: func(t testing.TB) bool {
+				return DiffErrIs(t, "id", "err", wrapped, pathErr)
+			},
+		},
+		{
+			ID: MkID("DiffErrIs, joined"),
+			f: func(t testing.TB) bool {
+				return DiffErrIs(t, "id", "err",
+					errors.Join(errors.New("first"),
+						fmt.Errorf("second: %w", errSentinel)),
+					errSentinel)
+			},
+		},
+		{
+			ID: MkID("DiffErrIs"),
+			f: func(t testing.TB) bool {
+				return DiffErrIs(t, "id", "err",
+					fmt.Errorf("b: %w", errors.New("a")), errSentinel)
+			},
+			expLog: `id
+	: expected err to match (using errors.Is): *errors.errorString: "s"
+	:   actual err:
+	:	*fmt.wrapError: "b: a"
+	:	  *errors.errorString: "a"
+	: err is incorrect
+`,
+		},
+		{
+			ID: MkID("DiffErrIs, nil error"),
+			f: func(t testing.TB) bool {
+				return DiffErrIs(t, "id", "err", nil, errSentinel)
+			},
+			expLog: `id
+	: expected err to match (using errors.Is): *errors.errorString: "s"
+	:   actual err:
+	:	nil
+	: err is incorrect
+`,
+		},
+		{
+			ID: MkID("DiffErrAs, wrapped"),
+			f: func(t testing.TB) bool {
+				return DiffErrAs[*fs.PathError](t, "id", "err", wrapped)
+			},
+		},
+		{
+			ID: MkID("DiffErrAs"),
+			f: func(t testing.TB) bool {
+				return DiffErrAs[*fs.PathError](t, "id", "err",
+					errors.Join(errSentinel, errors.New("a")))
+			},
+			expLog: `id
+	: expected err to match (using errors.As): *fs.PathError
+	:   actual err:
+	:	*errors.joinError: "s\na"
+	:	  *errors.errorString: "s"
+	:	  *errors.errorString: "a"
+	: err is incorrect
+`,
+		},
+		{
+			ID: MkID("DiffErrTree, same tree"),
+			f: func(t testing.TB) bool {
+				return DiffErrTree(t, "id", "err", mkTree(), mkTree())
+			},
+		},
+		{
+			ID: MkID("DiffErrTree, both nil"),
+			f: func(t testing.TB) bool {
+				return DiffErrTree(t, "id", "err", nil, nil)
+			},
+		},
+		{
+			ID: MkID("DiffErrTree, joined"),
+			f: func(t testing.TB) bool {
+				return DiffErrTree(t, "id", "err",
+					errors.Join(errors.New("a"),
+						fmt.Errorf("w: %w", errSentinel)),
+					errors.Join(errors.New("a"), pathErr))
+			},
+			expLog: `id
+	: err error trees differ
+	:   expected                        | actual
+	: * *errors.joinError: "a\nop p: e" | *errors.joinError: "a\nw: s"
+	:     *errors.errorString: "a"      |   *errors.errorString: "a"
+	: *   *fs.PathError: "op p: e"      |   *fmt.wrapError: "w: s"
+	: *     *errors.errorString: "e"    |     *errors.errorString: "s"
+	: err is incorrect
+`,
+		},
+		{
+			ID: MkID("DiffErrTree, multi-level wrapping"),
+			f: func(t testing.TB) bool {
+				return DiffErrTree(t, "id", "err",
+					fmt.Errorf("c: %w",
+						fmt.Errorf("b: %w", fmt.Errorf("w: %w", errSentinel))),
+					fmt.Errorf("c: %w", fmt.Errorf("b: %w", pathErr)))
+			},
+			expLog: `id
+	: err error trees differ
+	:   expected                        | actual
+	: * *fmt.wrapError: "c: b: op p: e" | *fmt.wrapError: "c: b: w: s"
+	: *   *fmt.wrapError: "b: op p: e"  |   *fmt.wrapError: "b: w: s"
+	: *     *fs.PathError: "op p: e"    |     *fmt.wrapError: "w: s"
+	: *       *errors.errorString: "e"  |       *errors.errorString: "s"
+	: err is incorrect
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		ft := &FakeTB{TB: t}
+		ft.CheckResult(t, tc.IDStr(), tc.f(ft), tc.expLog)
+	}
+}
+
+func TestErrTreeLines(t *testing.T) {
+	testCases := []struct {
+		ID
+		err    error
+		expVal []string
+	}{
+		{
+			ID:     MkID("nil"),
+			expVal: []string{"nil"},
+		},
+		{
+			ID:     MkID("simple"),
+			err:    errors.New("a"),
+			expVal: []string{`*errors.errorString: "a"`},
+		},
+		{
+			ID:  MkID("wrapped"),
+			err: fmt.Errorf("b: %w", errors.New("a")),
+			expVal: []string{
+				`*fmt.wrapError: "b: a"`,
+				`  *errors.errorString: "a"`,
+			},
+		},
+		{
+			ID: MkID("joined"),
+			err: fmt.Errorf("c: %w",
+				errors.Join(errors.New("a"), fmt.Errorf("b: %w", fs.ErrExist))),
+			expVal: []string{
+				`*fmt.wrapError: "c: a\nb: file already exists"`,
+				`  *errors.joinError: "a\nb: file already exists"`,
+				`    *errors.errorString: "a"`,
+				`    *fmt.wrapError: "b: file already exists"`,
+				`      *errors.errorString: "file already exists"`,
+			},
+		},
+		{
+			ID: MkID("multiple %w"),
+			err: fmt.Errorf("%w, %w",
+				errors.New("a"), errors.New("b")),
+			expVal: []string{
+				`*fmt.wrapErrors: "a, b"`,
+				`  *errors.errorString: "a"`,
+				`  *errors.errorString: "b"`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		DiffStringSlice(t, tc.IDStr(), "error tree",
+			errTreeLines(tc.err), tc.expVal)
+	}
+}
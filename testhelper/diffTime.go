@@ -0,0 +1,133 @@
+package testhelper
+
+import (
+	"testing"
+	"time"
+)
+
+// DiffTimeCfg holds configuration details which control how times are
+// compared by the Diff method. The zero value compares the times exactly
+// (ignoring any monotonic clock readings) and does not compare their
+// locations; this is how the DiffTime func compares times.
+//
+//	Tolerance gives the largest difference between the times which is
+//	allowed. If the times differ by no more than this they are taken to
+//	be the same.
+//
+//	Truncate, if greater than zero, causes both times to be truncated to
+//	a multiple of this duration before they are compared (see the
+//	Truncate method of time.Time).
+//
+//	Round, if greater than zero, causes both times to be rounded to the
+//	nearest multiple of this duration before they are compared (see the
+//	Round method of time.Time). If both Truncate and Round are given the
+//	times are truncated and then rounded.
+//
+//	SameLocation, if set, causes the times to be reported as different if
+//	their locations have different names, even if they represent the same
+//	instant.
+//
+//	UseMonotonic, if set, causes any monotonic clock readings to be used
+//	when both times have them (in the same way as the Sub method of
+//	time.Time). Otherwise the monotonic clock readings are removed and
+//	the wall clock times are compared.
+type DiffTimeCfg struct {
+	Tolerance    time.Duration
+	Truncate     time.Duration
+	Round        time.Duration
+	SameLocation bool
+	UseMonotonic bool
+}
+
+// prepare returns the time adjusted as required before it is compared
+func (dtc DiffTimeCfg) prepare(tm time.Time) time.Time {
+	if !dtc.UseMonotonic {
+		tm = tm.Round(0)
+	}
+
+	if dtc.Truncate > 0 {
+		tm = tm.Truncate(dtc.Truncate)
+	}
+
+	if dtc.Round > 0 {
+		tm = tm.Round(dtc.Round)
+	}
+
+	return tm
+}
+
+// timeStr returns the time formatted for reporting. Any monotonic clock
+// reading is not shown.
+func timeStr(tm time.Time) string {
+	return tm.Round(0).String()
+}
+
+// Diff compares the actual against the expected value and reports an error
+// if they differ, as determined by the DiffTimeCfg.
+func (dtc DiffTimeCfg) Diff(t testing.TB, id, name string,
+	act, exp time.Time,
+) bool {
+	t.Helper()
+
+	actT, expT := dtc.prepare(act), dtc.prepare(exp)
+	d := actT.Sub(expT)
+	timeDiffers := d > dtc.Tolerance || d < -dtc.Tolerance
+	locDiffers := dtc.SameLocation &&
+		act.Location().String() != exp.Location().String()
+
+	if !timeDiffers && !locDiffers {
+		return false
+	}
+
+	f := newStrFailure(id, "DiffTime", name, timeStr(act), timeStr(exp))
+	f.logf("\t: expected %s: %s\n", name, timeStr(exp))
+	f.logf("\t:   actual %s: %s\n", name, timeStr(act))
+
+	if timeDiffers {
+		if dtc.Truncate > 0 || dtc.Round > 0 {
+			f.logf("\t: compared as: expected: %s, actual: %s\n",
+				timeStr(expT), timeStr(actT))
+		}
+
+		f.logf("\t: difference: %s\n", d)
+
+		if dtc.Tolerance != 0 {
+			f.logf("\t:  tolerance: %s\n", dtc.Tolerance)
+		}
+	}
+
+	if locDiffers {
+		f.logf("\t: the locations differ: expected: %s, actual: %s\n",
+			exp.Location(), act.Location())
+	}
+
+	f.report(t, "\t: %s is incorrect\n", name)
+
+	return true
+}
+
+// DiffDuration compares the actual against the expected value and reports
+// an error if they differ by more than the tolerance
+func DiffDuration(t testing.TB, id, name string,
+	act, exp, tolerance time.Duration,
+) bool {
+	t.Helper()
+
+	d := act - exp
+	if d <= tolerance && d >= -tolerance {
+		return false
+	}
+
+	f := newStrFailure(id, "DiffDuration", name, act.String(), exp.String())
+	f.logf("\t: expected %s: %s\n", name, exp)
+	f.logf("\t:   actual %s: %s\n", name, act)
+	f.logf("\t: difference: %s\n", d)
+
+	if tolerance != 0 {
+		f.logf("\t:  tolerance: %s\n", tolerance)
+	}
+
+	f.report(t, "\t: %s is incorrect\n", name)
+
+	return true
+}
@@ -0,0 +1,208 @@
+package testhelper_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestDiffTimeCfg(t *testing.T) {
+	base := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	est := time.FixedZone("EST", -5*60*60)
+	now := time.Now()
+
+	testCases := []struct {
+		testhelper.ID
+		dtc      testhelper.DiffTimeCfg
+		act, exp time.Time
+		expLog   string
+	}{
+		{
+			ID:  testhelper.MkID("exactly equal"),
+			act: base,
+			exp: base,
+		},
+		{
+			ID:  testhelper.MkID("same instant, different location"),
+			act: base.In(est),
+			exp: base,
+		},
+		{
+			ID:  testhelper.MkID("monotonic reading ignored"),
+			act: now,
+			exp: now.Round(0),
+		},
+		{
+			ID:  testhelper.MkID("within tolerance"),
+			dtc: testhelper.DiffTimeCfg{Tolerance: 50 * time.Millisecond},
+			act: base.Add(-49 * time.Millisecond),
+			exp: base,
+		},
+		{
+			ID:  testhelper.MkID("truncated"),
+			dtc: testhelper.DiffTimeCfg{Truncate: time.Second},
+			act: base.Add(999 * time.Millisecond),
+			exp: base,
+		},
+		{
+			ID:  testhelper.MkID("rounded"),
+			dtc: testhelper.DiffTimeCfg{Round: time.Second},
+			act: base.Add(-400 * time.Millisecond),
+			exp: base.Add(300 * time.Millisecond),
+		},
+		{
+			ID:  testhelper.MkID("same location"),
+			dtc: testhelper.DiffTimeCfg{SameLocation: true},
+			act: base.In(est),
+			exp: base.In(time.FixedZone("EST", -5*60*60)),
+		},
+		{
+			ID:  testhelper.MkID("monotonic reading used"),
+			dtc: testhelper.DiffTimeCfg{UseMonotonic: true},
+			act: now.Add(time.Second),
+			exp: now.Add(time.Second),
+		},
+		{
+			ID:  testhelper.MkID("differ"),
+			act: base.Add(time.Second),
+			exp: base,
+			expLog: "id\n" +
+				"\t: expected time: 2024-01-02 03:04:05 +0000 UTC\n" +
+				"\t:   actual time: 2024-01-02 03:04:06 +0000 UTC\n" +
+				"\t: difference: 1s\n" +
+				"\t: time is incorrect\n",
+		},
+		{
+			ID:  testhelper.MkID("outside tolerance"),
+			dtc: testhelper.DiffTimeCfg{Tolerance: 50 * time.Millisecond},
+			act: base.Add(-51 * time.Millisecond),
+			exp: base,
+			expLog: "id\n" +
+				"\t: expected time: 2024-01-02 03:04:05 +0000 UTC\n" +
+				"\t:   actual time: 2024-01-02 03:04:04.949 +0000 UTC\n" +
+				"\t: difference: -51ms\n" +
+				"\t:  tolerance: 50ms\n" +
+				"\t: time is incorrect\n",
+		},
+		{
+			ID:  testhelper.MkID("locations differ"),
+			dtc: testhelper.DiffTimeCfg{SameLocation: true},
+			act: base.In(est),
+			exp: base,
+			expLog: "id\n" +
+				"\t: expected time: 2024-01-02 03:04:05 +0000 UTC\n" +
+				"\t:   actual time: 2024-01-01 22:04:05 -0500 EST\n" +
+				"\t: the locations differ: expected: UTC, actual: EST\n" +
+				"\t: time is incorrect\n",
+		},
+		{
+			ID: testhelper.MkID("truncated, still differ"),
+			dtc: testhelper.DiffTimeCfg{
+				Truncate:  time.Second,
+				Tolerance: 500 * time.Millisecond,
+			},
+			act: base.Add(1999 * time.Millisecond),
+			exp: base.Add(100 * time.Millisecond),
+			expLog: "id\n" +
+				"\t: expected time: 2024-01-02 03:04:05.1 +0000 UTC\n" +
+				"\t:   actual time: 2024-01-02 03:04:06.999 +0000 UTC\n" +
+				"\t: compared as:" +
+				" expected: 2024-01-02 03:04:05 +0000 UTC," +
+				" actual: 2024-01-02 03:04:06 +0000 UTC\n" +
+				"\t: difference: 1s\n" +
+				"\t:  tolerance: 500ms\n" +
+				"\t: time is incorrect\n",
+		},
+		{
+			ID:  testhelper.MkID("rounded, still differ"),
+			dtc: testhelper.DiffTimeCfg{Round: time.Second},
+			act: base.Add(-600 * time.Millisecond),
+			exp: base.Add(400 * time.Millisecond),
+			expLog: "id\n" +
+				"\t: expected time: 2024-01-02 03:04:05.4 +0000 UTC\n" +
+				"\t:   actual time: 2024-01-02 03:04:04.4 +0000 UTC\n" +
+				"\t: compared as:" +
+				" expected: 2024-01-02 03:04:05 +0000 UTC," +
+				" actual: 2024-01-02 03:04:04 +0000 UTC\n" +
+				"\t: difference: -1s\n" +
+				"\t: time is incorrect\n",
+		},
+		{
+			ID: testhelper.MkID("time and locations differ"),
+			dtc: testhelper.DiffTimeCfg{
+				Tolerance:    time.Second,
+				SameLocation: true,
+			},
+			act: base.Add(2 * time.Second).In(est),
+			exp: base,
+			expLog: "id\n" +
+				"\t: expected time: 2024-01-02 03:04:05 +0000 UTC\n" +
+				"\t:   actual time: 2024-01-01 22:04:07 -0500 EST\n" +
+				"\t: difference: 2s\n" +
+				"\t:  tolerance: 1s\n" +
+				"\t: the locations differ: expected: UTC, actual: EST\n" +
+				"\t: time is incorrect\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		ft := &fakeTB{TB: t}
+		ft.CheckResult(t, tc.IDStr(),
+			tc.dtc.Diff(ft, "id", "time", tc.act, tc.exp), tc.expLog)
+	}
+
+	if testhelper.DiffTime(t, "test: DiffTime", "time", now, now.Round(0)) {
+		t.Error("\t: DiffTime should have found no differences")
+	}
+}
+
+func TestDiffDuration(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		act, exp, tolerance time.Duration
+		expLog              string
+	}{
+		{
+			ID:        testhelper.MkID("within tolerance"),
+			act:       time.Second,
+			exp:       1100 * time.Millisecond,
+			tolerance: 100 * time.Millisecond,
+		},
+		{
+			ID:  testhelper.MkID("exact"),
+			act: time.Minute,
+			exp: 60 * time.Second,
+		},
+		{
+			ID:  testhelper.MkID("differ"),
+			act: time.Second,
+			exp: time.Minute,
+			expLog: "id\n" +
+				"\t: expected duration: 1m0s\n" +
+				"\t:   actual duration: 1s\n" +
+				"\t: difference: -59s\n" +
+				"\t: duration is incorrect\n",
+		},
+		{
+			ID:        testhelper.MkID("outside tolerance"),
+			act:       1200 * time.Millisecond,
+			exp:       time.Second,
+			tolerance: 100 * time.Millisecond,
+			expLog: "id\n" +
+				"\t: expected duration: 1s\n" +
+				"\t:   actual duration: 1.2s\n" +
+				"\t: difference: 200ms\n" +
+				"\t:  tolerance: 100ms\n" +
+				"\t: duration is incorrect\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		ft := &fakeTB{TB: t}
+		ft.CheckResult(t, tc.IDStr(),
+			testhelper.DiffDuration(ft, "id", "duration",
+				tc.act, tc.exp, tc.tolerance),
+			tc.expLog)
+	}
+}
@@ -1,20 +1,90 @@
 package testhelper
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"slices"
+	"strings"
+	"unsafe"
 )
 
 // DiffValErr holds an error reflecting the difference between two interface
-// values. It is the type of error returned by the DiffVals func
+// values. It is the type of error returned by the DiffVals func.
+//
+// The Path records where the difference was found, Act and Exp hold the
+// actual and expected values at that point (either may be invalid, for
+// instance where one of the values is nil) and Msg describes the
+// difference. Note that the Act and Exp values cannot be converted back
+// into interface values if they were reached through an unexported struct
+// field (see the CanInterface method of reflect.Value).
 type DiffValErr struct {
-	dl  deepLoc
-	msg string
+	Path Path
+	Act  reflect.Value
+	Exp  reflect.Value
+	Msg  string
 }
 
-// Error returns a string expressing the DiffValErr
+// Error returns a string expressing the DiffValErr. If the difference is
+// within an unexported struct field this is noted as such differences are
+// often unexpected.
 func (dve DiffValErr) Error() string {
-	return dve.dl.fullName + ": " + dve.msg
+	if name, ok := dve.Path.unexportedField(); ok {
+		return dve.Path.String() + ": " + dve.Msg +
+			" (in the unexported field: " + name + ";" +
+			" see DiffValsCfg.IgnoreUnexported)"
+	}
+
+	return dve.Path.String() + ": " + dve.Msg
+}
+
+// DiffValErrs holds the differences found by the DiffValsAll func. At most
+// MaxReportedDiffs differences are retained, the Count records the total
+// number of differences found.
+type DiffValErrs struct {
+	Errs  []DiffValErr
+	Count int
+}
+
+// Error returns a string expressing the DiffValErrs, each difference is
+// reported on a separate line.
+func (dves DiffValErrs) Error() string {
+	msgs := make([]string, 0, len(dves.Errs)+1)
+
+	for _, dve := range dves.Errs {
+		msgs = append(msgs, dve.Error())
+	}
+
+	if dves.Count > len(dves.Errs) {
+		msgs = append(msgs,
+			fmt.Sprintf("... %d differences found, %d not shown",
+				dves.Count, dves.Count-len(dves.Errs)))
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the individual differences as a slice of errors. This
+// allows the errors.As and errors.Is funcs to find the individual
+// DiffValErr values.
+func (dves DiffValErrs) Unwrap() []error {
+	errs := make([]error, 0, len(dves.Errs))
+
+	for _, dve := range dves.Errs {
+		errs = append(errs, dve)
+	}
+
+	return errs
+}
+
+// add records the difference, it only retains the first MaxReportedDiffs
+// differences but counts all of them.
+func (dves *DiffValErrs) add(dve DiffValErr) {
+	dves.Count++
+
+	if len(dves.Errs) < MaxReportedDiffs {
+		dves.Errs = append(dves.Errs, dve)
+	}
 }
 
 // visit represents a previously visited pair of pointers and allows us to
@@ -30,27 +100,43 @@ type visit struct {
 // checked against this skip list. This is to allow comparisons to be made
 // between values where some of the fields in a structure may be different
 // but we don't care.
+//
+// If the found member is non-nil then differences are recorded there and
+// the comparison continues rather than stopping at the first difference.
 type deepLoc struct {
 	depth      int
-	fullName   string
+	path       Path
 	currentLoc []string
 	skipLocs   [][]string
 	loop       map[visit]bool
+	found      *DiffValErrs
+	cfg        *DiffValsCfg
 }
 
 // makeDeepLoc returns a correctly formed deepLoc
-func makeDeepLoc(sl [][]string) deepLoc {
+func makeDeepLoc(cfg *DiffValsCfg, sl [][]string) deepLoc {
 	return deepLoc{
-		fullName: "this",
 		skipLocs: sl,
 		loop:     map[visit]bool{},
+		cfg:      cfg,
 	}
 }
 
 // String returns the full name of the location which includes index values
 // which are not checked when deciding if the location should be skipped
 func (dl deepLoc) String() string {
-	return dl.fullName
+	return dl.path.String()
+}
+
+// mkErr returns a DiffValErr describing the difference between the actual
+// and expected values at the current location.
+func (dl deepLoc) mkErr(actVal, expVal reflect.Value, msg string) DiffValErr {
+	return DiffValErr{
+		Path: dl.path,
+		Act:  actVal,
+		Exp:  expVal,
+		Msg:  msg,
+	}
 }
 
 // incr increments the number of times the code has called diffVals and
@@ -61,7 +147,7 @@ func (dl *deepLoc) incr() {
 	dl.depth++
 
 	if dl.depth > maxDepth {
-		panic(DiffValErr{dl: *dl, msg: "undetected loop"})
+		panic(DiffValErr{Path: dl.path, Msg: "undetected loop"})
 	}
 }
 
@@ -81,32 +167,84 @@ func (dl *deepLoc) isALoop(v visit) bool {
 	return false
 }
 
-// addName adds the name to the formatted name and to the currentLoc. It
-// returns a copy of the amended value.
+// addName adds the name to the path and to the currentLoc. It returns a
+// copy of the amended value.
 func addName(dl deepLoc, s string) deepLoc {
-	dl.fullName += "." + s
-	dl.currentLoc = append(dl.currentLoc, s)
+	dl.path = dl.path.add(PathElem{Kind: PathField, Name: s})
+	dl.currentLoc = append(slices.Clip(dl.currentLoc), s)
 
 	return dl
 }
 
-// addIdx adds the index to the formatted name. It returns a copy of the
-// amended value.
+// addIdx adds the index to the path. It returns a copy of the amended value.
 func addIdx(dl deepLoc, i int) deepLoc {
-	dl.fullName += fmt.Sprintf("[%d]", i)
+	dl.path = dl.path.add(PathElem{Kind: PathIndex, Index: i})
+	return dl
+}
+
+// addKey adds the key to the path. It returns a copy of the amended value.
+func addKey(dl deepLoc, k reflect.Value) deepLoc {
+	dl.path = dl.path.add(PathElem{Kind: PathMapKey, Key: k})
+	return dl
+}
+
+// addPtrDeref adds a pointer dereference to the path. It returns a copy of
+// the amended value.
+func addPtrDeref(dl deepLoc) deepLoc {
+	dl.path = dl.path.add(PathElem{Kind: PathPtrDeref})
+	return dl
+}
+
+// addIfaceUnwrap adds an interface unwrapping to the path. It returns a
+// copy of the amended value.
+func addIfaceUnwrap(dl deepLoc) deepLoc {
+	dl.path = dl.path.add(PathElem{Kind: PathIfaceUnwrap})
 	return dl
 }
 
-// addKey adds the key to the formatted name. It returns a copy of the
+// addTransform adds a transformation to the path. It returns a copy of the
 // amended value.
-func addKey(dl deepLoc, k any) deepLoc {
-	dl.fullName += fmt.Sprintf("[%v]", k)
+func addTransform(dl deepLoc, name string) deepLoc {
+	dl.path = dl.path.add(PathElem{Kind: PathTransform, Name: name})
 	return dl
 }
 
+// record adds the error to the collection of differences found if all the
+// differences are being collected, in which case it returns nil so that the
+// comparison can continue. Otherwise it returns the error unchanged.
+func (dl deepLoc) record(err error) error {
+	if err == nil || dl.found == nil {
+		return err
+	}
+
+	var dve DiffValErr
+	if !errors.As(err, &dve) {
+		return err
+	}
+
+	dl.found.add(dve)
+
+	return nil
+}
+
+// equalAt returns true if the values are equal. The comparison is made at
+// the given location but no differences are recorded and any loops
+// detected are not shared with the main comparison. This allows values to
+// be compared speculatively.
+func equalAt(actVal, expVal reflect.Value, dl deepLoc) bool {
+	dl.found = nil
+	dl.loop = map[visit]bool{}
+
+	return diffVals(actVal, expVal, dl) == nil
+}
+
 // skip returns true if the current location is in the list of locations to
-// be skipped.
+// be skipped or matches any of the ignore patterns in the configuration.
 func (dl deepLoc) skip() bool {
+	if matchesAny(dl.cfg.ignore, dl.path) {
+		return true
+	}
+
 	for _, sl := range dl.skipLocs {
 		if len(sl) > len(dl.currentLoc) {
 			continue
@@ -131,33 +269,48 @@ func (dl deepLoc) skip() bool {
 
 // DiffVals compares the actual and expected values and returns an error if
 // they are different. This differs from the reflect package function
-// DeepEqual in that the error shows which fields are different.
+// DeepEqual in that the error shows which fields are different. As for the
+// == operator, a floating point NaN value is not equal to any value, not
+// even another NaN; use the DiffVals method on a DiffValsCfg with the
+// NaNsEqual field of its FloatTol set to take two NaN values to be equal.
 //
 // The ignore argument holds the names of fields to not compare, the slice
 // represents a chain of names in nested structs. So, for instance an ignore
 // value containing the pair of values ["a", "b"] means to not compare the
-// field called "b" in the sub-struct called "a".
+// field called "b" in the sub-struct called "a". Use the DiffVals method
+// on a DiffValsCfg for more precise control over what is not compared.
 func DiffVals(actVal, expVal any, ignore ...[]string) error {
+	return DiffValsCfg{}.diffVals(actVal, expVal, ignore)
+}
+
+// DiffValsAll compares the actual and expected values in the same way as
+// DiffVals but rather than stopping at the first difference it carries on
+// and finds all the differences. If any are found it returns a DiffValErrs
+// holding the first MaxReportedDiffs differences and a count of all of them.
+//
+// The ignore argument is used in the same way as for DiffVals.
+func DiffValsAll(actVal, expVal any, ignore ...[]string) error {
+	return DiffValsCfg{ReportAll: true}.diffVals(actVal, expVal, ignore)
+}
+
+// diffValsTop checks the top-level values for nil and then compares them
+func diffValsTop(actVal, expVal any, dl deepLoc) error {
 	if actVal == nil && expVal == nil {
 		return nil
 	}
 
-	dl := makeDeepLoc(ignore)
 	if actVal == nil {
-		return DiffValErr{
-			dl:  dl,
-			msg: "the actual value is nil, the expected value is not",
-		}
+		return dl.mkErr(reflect.Value{}, reflect.ValueOf(expVal),
+			"the actual value is nil, the expected value is not")
 	}
 
 	if expVal == nil {
-		return DiffValErr{
-			dl:  dl,
-			msg: "the expected value is nil, the actual value is not",
-		}
+		return dl.mkErr(reflect.ValueOf(actVal), reflect.Value{},
+			"the expected value is nil, the actual value is not")
 	}
 
-	return diffVals(reflect.ValueOf(actVal), reflect.ValueOf(expVal), dl)
+	return diffVals(addressable(reflect.ValueOf(actVal)),
+		addressable(reflect.ValueOf(expVal)), dl)
 }
 
 // diffVals returns an error if the two values differ, either in type or value
@@ -173,30 +326,35 @@ func diffVals(actVal, expVal reflect.Value, dl deepLoc) error { //nolint:cyclop
 	}
 
 	if !actVal.IsValid() {
-		return DiffValErr{
-			dl:  dl,
-			msg: "the actual value is invalid, the expected value is not",
-		}
+		return dl.mkErr(actVal, expVal,
+			"the actual value is invalid, the expected value is not")
 	}
 
 	if !expVal.IsValid() {
-		return DiffValErr{
-			dl:  dl,
-			msg: "the expected value is invalid, the actual value is not",
-		}
+		return dl.mkErr(actVal, expVal,
+			"the expected value is invalid, the actual value is not")
 	}
 
 	actType := actVal.Type()
 	expType := expVal.Type()
 
 	if actType != expType {
-		return DiffValErr{
-			dl: dl,
-			msg: fmt.Sprintf("types differ. Actual: %s, expected: %s",
-				actType, expType),
+		return dl.mkErr(actVal, expVal,
+			fmt.Sprintf("types differ. Actual: %s, expected: %s",
+				actType, expType))
+	}
+
+	if tr, ok := dl.cfg.transformerFor(actType, dl.path); ok {
+		if act, exp, ok := accessiblePair(actVal, expVal); ok {
+			return diffVals(tr.trFunc(act), tr.trFunc(exp),
+				addTransform(dl, tr.name))
 		}
 	}
 
+	if compared, err := diffValsCustom(actVal, expVal, dl); compared {
+		return err
+	}
+
 	switch actType.Kind() {
 	case reflect.Invalid:
 		return nil
@@ -217,10 +375,15 @@ func diffVals(actVal, expVal reflect.Value, dl deepLoc) error { //nolint:cyclop
 	case reflect.String:
 		return diffValsString(actVal, expVal, dl)
 	case reflect.Interface:
-		return diffVals(actVal.Elem(), expVal.Elem(), dl)
+		return diffVals(ifaceElem(actVal), ifaceElem(expVal),
+			addIfaceUnwrap(dl))
 	case reflect.Array:
 		return diffValsArray(actVal, expVal, dl)
 	case reflect.Slice:
+		if err := diffValsNilness(actVal, expVal, dl); err != nil {
+			return err
+		}
+
 		if valsMustBeEqual(actVal.Pointer(), expVal.Pointer(), actType, dl) {
 			return nil
 		}
@@ -239,8 +402,13 @@ func diffVals(actVal, expVal reflect.Value, dl deepLoc) error { //nolint:cyclop
 			return nil
 		}
 
-		return diffVals(actVal.Elem(), expVal.Elem(), dl)
+		return diffVals(ptrElem(actVal, dl), ptrElem(expVal, dl),
+			addPtrDeref(dl))
 	case reflect.Map:
+		if err := diffValsNilness(actVal, expVal, dl); err != nil {
+			return err
+		}
+
 		if valsMustBeEqual(actVal.Pointer(), expVal.Pointer(), actType, dl) {
 			return nil
 		}
@@ -250,10 +418,136 @@ func diffVals(actVal, expVal reflect.Value, dl deepLoc) error { //nolint:cyclop
 		return diffValsStruct(actVal, expVal, dl)
 	}
 
-	panic(DiffValErr{
-		dl:  dl,
-		msg: fmt.Sprintf("unchecked value kind: %s", actType.Kind()),
-	})
+	panic(dl.mkErr(actVal, expVal,
+		fmt.Sprintf("unchecked value kind: %s", actType.Kind())))
+}
+
+// addressable returns the value if it is addressable, otherwise it returns
+// an addressable copy. Any fields of a struct value (and elements of an
+// array value) are then also addressable. This is applied to the top-level
+// values only so that values held in unexported struct fields can be made
+// accessible if they are to be passed to a comparator func, a transformer
+// or an Equal method. It does not change the values being compared.
+func addressable(v reflect.Value) reflect.Value {
+	if v.CanAddr() || !v.CanInterface() {
+		return v
+	}
+
+	addressableVal := reflect.New(v.Type()).Elem()
+	addressableVal.Set(v)
+
+	return addressableVal
+}
+
+// accessiblePair returns values equivalent to the supplied values but which
+// can be converted to interface values, bypassing the restriction on values
+// held in unexported struct fields if necessary. It returns false if this is
+// not possible. The values returned must only be used to call a comparator
+// func, a transformer or an Equal method; they should never be returned to
+// the caller of DiffVals.
+func accessiblePair(actVal, expVal reflect.Value,
+) (reflect.Value, reflect.Value, bool) {
+	act, actOK := accessible(actVal)
+	exp, expOK := accessible(expVal)
+
+	return act, exp, actOK && expOK
+}
+
+// accessible returns a value equivalent to the supplied value but which can
+// be converted to an interface value. It returns false if this is not
+// possible.
+func accessible(v reflect.Value) (reflect.Value, bool) {
+	if v.CanInterface() {
+		return v, true
+	}
+
+	if !v.CanAddr() {
+		return v, false
+	}
+
+	return reflect.NewAt(v.Type(),
+		unsafe.Pointer(v.UnsafeAddr())).Elem(), true //nolint:gosec
+}
+
+// readable returns the value if it can be converted to an interface value.
+// Otherwise, if it is possible, it returns an accessible equivalent and
+// true; any values taken from this must be made read-only (see readOnly)
+// before they are compared.
+func readable(v reflect.Value) (reflect.Value, bool) {
+	if v.CanInterface() {
+		return v, false
+	}
+
+	if av, ok := accessible(v); ok {
+		return av, true
+	}
+
+	return v, false
+}
+
+// readOnlyBox is used to construct read-only values, see readOnly
+type readOnlyBox struct {
+	ptr any
+}
+
+// readOnly returns an addressable copy of the value which, as if it had
+// been reached through an unexported struct field, cannot be converted to
+// an interface value or changed.
+func readOnly(v reflect.Value) reflect.Value {
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+
+	return reflect.ValueOf(readOnlyBox{ptr: ptr.Interface()}).
+		Field(0).Elem().Elem()
+}
+
+// readOnlyIf returns a read-only copy of the value if ro is true and the
+// value is valid, otherwise it returns the value unchanged.
+func readOnlyIf(v reflect.Value, ro bool) reflect.Value {
+	if !ro || !v.IsValid() {
+		return v
+	}
+
+	return readOnly(v)
+}
+
+// ifaceElem returns the value held in the interface value. If the interface
+// value was reached through an unexported struct field the value returned
+// is an addressable, read-only copy so that any values it holds in
+// unexported fields can still be passed to a comparator func, a transformer
+// or an Equal method.
+func ifaceElem(v reflect.Value) reflect.Value {
+	if v.IsNil() {
+		return v.Elem()
+	}
+
+	av, ro := readable(v)
+
+	return readOnlyIf(av.Elem(), ro)
+}
+
+// diffValsCustom compares the values using any comparator func registered
+// for their type or, if so configured, using their Equal method. It returns
+// true if the values were compared this way and any difference found.
+func diffValsCustom(actVal, expVal reflect.Value, dl deepLoc) (bool, error) {
+	cmpFunc, how := dl.cfg.comparatorFor(actVal, expVal)
+	if cmpFunc == nil {
+		return false, nil
+	}
+
+	act, exp, ok := accessiblePair(actVal, expVal)
+	if !ok {
+		return false, nil
+	}
+
+	if cmpFunc(act, exp) {
+		return true, nil
+	}
+
+	return true, dl.mkErr(actVal, expVal,
+		fmt.Sprintf("%s values differ (compared using %s)."+
+			" Actual: %s, expected: %s",
+			actVal.Type(), how, prettyPrintVal(act), prettyPrintVal(exp)))
 }
 
 // valsMustBeEqual returns true if the pointers are the same or if they have
@@ -270,25 +564,46 @@ func valsMustBeEqual(actPtr, expPtr uintptr, t reflect.Type, dl deepLoc) bool {
 	return false
 }
 
-// diffValsMap returns an error if the two map values differ
+// diffValsMap returns an error if the two map values differ. Any keys
+// present in only one of the maps are reported and then the values for
+// the keys common to both are compared. The keys are compared in a
+// deterministic order so that the differences reported are stable.
 func diffValsMap(actVal, expVal reflect.Value, dl deepLoc) error {
-	aMapKeys := actVal.MapKeys()
-	eMapKeys := expVal.MapKeys()
-	aLen := len(aMapKeys)
-	eLen := len(eMapKeys)
+	actMap, actRO := readable(actVal)
+	expMap, expRO := readable(expVal)
 
-	if aLen != eLen {
-		return DiffValErr{
-			dl: dl,
-			msg: fmt.Sprintf("map lengths differ. Actual: %d, expected: %d",
-				aLen, eLen),
+	extra := []reflect.Value{}
+	common := []reflect.Value{}
+
+	for _, k := range sortedMapKeys(actMap) {
+		if expMap.MapIndex(k).IsValid() {
+			common = append(common, k)
+		} else {
+			extra = append(extra, k)
 		}
 	}
 
-	for i := range aLen {
-		k := aMapKeys[i]
+	missing := []reflect.Value{}
+
+	for _, k := range sortedMapKeys(expMap) {
+		if !actMap.MapIndex(k).IsValid() {
+			missing = append(missing, k)
+		}
+	}
 
-		err := diffVals(actVal.MapIndex(k), expVal.MapIndex(k), addKey(dl, k))
+	if len(extra) > 0 || len(missing) > 0 {
+		err := dl.record(dl.mkErr(actVal, expVal, mapKeysMsg(extra, missing)))
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, k := range common {
+		err := dl.record(
+			diffVals(
+				readOnlyIf(actMap.MapIndex(k), actRO),
+				readOnlyIf(expMap.MapIndex(k), expRO),
+				addKey(dl, readOnlyIf(k, actRO))))
 		if err != nil {
 			return err
 		}
@@ -297,16 +612,52 @@ func diffValsMap(actVal, expVal reflect.Value, dl deepLoc) error {
 	return nil
 }
 
+// mapKeysMsg returns a message describing the keys present in only one of
+// the maps being compared
+func mapKeysMsg(extra, missing []reflect.Value) string {
+	msg := "map keys differ."
+
+	if len(extra) > 0 {
+		msg += " Present in actual but not expected: " + fmtKeys(extra)
+	}
+
+	if len(missing) > 0 {
+		if len(extra) > 0 {
+			msg += ","
+		}
+
+		msg += " Missing from actual: " + fmtKeys(missing)
+	}
+
+	return msg
+}
+
+// fmtKeys returns a string showing the keys
+func fmtKeys(keys []reflect.Value) string {
+	strs := make([]string, 0, len(keys))
+
+	for _, k := range keys {
+		strs = append(strs, prettyPrintVal(k))
+	}
+
+	return "[" + strings.Join(strs, " ") + "]"
+}
+
 // diffValsStruct returns an error if the two struct values differ
 func diffValsStruct(actVal, expVal reflect.Value, dl deepLoc) error {
 	// we know that both the expected and actual values have the same number
 	// of fields as their types are the same
+	t := actVal.Type()
 	fields := actVal.NumField()
 
 	for i := range fields {
-		fldName := actVal.Type().Field(i).Name
+		fld := t.Field(i)
+		if !dl.cfg.compareField(t, fld) {
+			continue
+		}
 
-		err := diffVals(actVal.Field(i), expVal.Field(i), addName(dl, fldName))
+		err := dl.record(
+			diffVals(actVal.Field(i), expVal.Field(i), addName(dl, fld.Name)))
 		if err != nil {
 			return err
 		}
@@ -317,19 +668,26 @@ func diffValsStruct(actVal, expVal reflect.Value, dl deepLoc) error {
 
 // diffValsSlice returns an error if the two slice values differ
 func diffValsSlice(actVal, expVal reflect.Value, dl deepLoc) error {
+	if matchesAny(dl.cfg.unordered, dl.path) {
+		return diffValsUnordered(actVal, expVal, dl)
+	}
+
+	if dl.cfg.UseSliceEdits {
+		return diffValsSliceEdits(actVal, expVal, dl)
+	}
+
 	aLen := actVal.Len()
 	eLen := expVal.Len()
 
 	if aLen != eLen {
-		return DiffValErr{
-			dl: dl,
-			msg: fmt.Sprintf("slice lengths differ. Actual: %d, expected: %d",
-				aLen, eLen),
-		}
+		return dl.mkErr(actVal, expVal,
+			fmt.Sprintf("slice lengths differ. Actual: %d, expected: %d",
+				aLen, eLen))
 	}
 
 	for i := range aLen {
-		err := diffVals(actVal.Index(i), expVal.Index(i), addIdx(dl, i))
+		err := dl.record(
+			diffVals(actVal.Index(i), expVal.Index(i), addIdx(dl, i)))
 		if err != nil {
 			return err
 		}
@@ -338,14 +696,144 @@ func diffValsSlice(actVal, expVal reflect.Value, dl deepLoc) error {
 	return nil
 }
 
+// diffValsUnordered returns an error if the two slice or array values
+// differ when the order of the elements is ignored. Each element of the
+// actual value is matched against the first unmatched, equal element of the
+// expected value. Any unmatched elements of either are reported.
+func diffValsUnordered(actVal, expVal reflect.Value, dl deepLoc) error {
+	aLen := actVal.Len()
+	eLen := expVal.Len()
+	matched := make([]bool, eLen)
+	unexpected := []int{}
+
+	for i := range aLen {
+		found := false
+
+		for j := range eLen {
+			if !matched[j] &&
+				equalAt(actVal.Index(i), expVal.Index(j), addIdx(dl, i)) {
+				matched[j] = true
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			unexpected = append(unexpected, i)
+		}
+	}
+
+	for _, i := range unexpected {
+		if err := dl.record(unexpectedElemErr(actVal, i, dl)); err != nil {
+			return err
+		}
+	}
+
+	for j := range eLen {
+		if matched[j] {
+			continue
+		}
+
+		if err := dl.record(missingElemErr(expVal, j, dl)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// diffValsSliceEdits returns an error if the two slice values differ. The
+// elements are matched (see SliceEdits) and any elements present in only
+// one of the slices are reported. Changed elements are compared in the
+// usual way and reported at the index in the actual slice.
+func diffValsSliceEdits(actVal, expVal reflect.Value, dl deepLoc) error {
+	edits := editScript(actVal.Len(), expVal.Len(),
+		func(ai, ei int) bool {
+			return equalAt(actVal.Index(ai), expVal.Index(ei), addIdx(dl, ai))
+		})
+
+	for _, e := range edits {
+		var err error
+
+		switch e.Op {
+		case EditInsert:
+			err = unexpectedElemErr(actVal, e.ActIdx, dl)
+		case EditDelete:
+			err = missingElemErr(expVal, e.ExpIdx, dl)
+		case EditChange:
+			err = diffVals(actVal.Index(e.ActIdx), expVal.Index(e.ExpIdx),
+				addIdx(dl, e.ActIdx))
+		}
+
+		if err = dl.record(err); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// unexpectedElemErr returns an error reporting that the i'th element of the
+// actual slice has no counterpart in the expected slice
+func unexpectedElemErr(actVal reflect.Value, i int, dl deepLoc) error {
+	return dl.mkErr(actVal.Index(i), reflect.Value{},
+		fmt.Sprintf("unexpected element, not in the expected values."+
+			" Actual[%d]: %s", i, prettyPrintVal(actVal.Index(i))))
+}
+
+// missingElemErr returns an error reporting that the i'th element of the
+// expected slice has no counterpart in the actual slice
+func missingElemErr(expVal reflect.Value, i int, dl deepLoc) error {
+	return dl.mkErr(reflect.Value{}, expVal.Index(i),
+		fmt.Sprintf("missing element, not in the actual values."+
+			" Expected[%d]: %s", i, prettyPrintVal(expVal.Index(i))))
+}
+
+// diffValsNilness returns an error if the NilPolicy requires nil and empty
+// values to be treated as different and just one of the slice or map values
+// is nil.
+func diffValsNilness(actVal, expVal reflect.Value, dl deepLoc) error {
+	if dl.cfg.NilPolicy != NilDiffersFromEmpty ||
+		actVal.IsNil() == expVal.IsNil() {
+		return nil
+	}
+
+	kind := actVal.Kind().String()
+
+	if actVal.IsNil() {
+		return dl.mkErr(actVal, expVal,
+			"the actual value is a nil "+kind+", the expected value is not")
+	}
+
+	return dl.mkErr(actVal, expVal,
+		"the expected value is a nil "+kind+", the actual value is not")
+}
+
+// ptrElem returns the value that the pointer points to. If the pointer is
+// nil and the NilPolicy is NilEqualsZero then the zero value of the type
+// pointed to is returned. Otherwise the invalid value is returned.
+func ptrElem(ptr reflect.Value, dl deepLoc) reflect.Value {
+	if ptr.IsNil() && dl.cfg.NilPolicy == NilEqualsZero {
+		return reflect.Zero(ptr.Type().Elem())
+	}
+
+	return ptr.Elem()
+}
+
 // diffValsArray returns an error if the two array values differ
 func diffValsArray(actVal, expVal reflect.Value, dl deepLoc) error {
+	if matchesAny(dl.cfg.unordered, dl.path) {
+		return diffValsUnordered(actVal, expVal, dl)
+	}
+
 	// we know both the expected and actual arrays have the same length
 	// as their types are the same
 	aLen := actVal.Len()
 
 	for i := range aLen {
-		err := diffVals(actVal.Index(i), expVal.Index(i), addIdx(dl, i))
+		err := dl.record(
+			diffVals(actVal.Index(i), expVal.Index(i), addIdx(dl, i)))
 		if err != nil {
 			return err
 		}
@@ -357,10 +845,7 @@ func diffValsArray(actVal, expVal reflect.Value, dl deepLoc) error {
 // diffValsPointer returns an error if the two pointer values differ
 func diffValsPointer(actVal, expVal reflect.Value, dl deepLoc) error {
 	if actVal.Pointer() != expVal.Pointer() {
-		return DiffValErr{
-			dl:  dl,
-			msg: "pointers differ",
-		}
+		return dl.mkErr(actVal, expVal, "pointers differ")
 	}
 
 	return nil
@@ -369,10 +854,7 @@ func diffValsPointer(actVal, expVal reflect.Value, dl deepLoc) error {
 // diffValsUintptr returns an error if the two uintptr values differ
 func diffValsUintptr(actVal, expVal reflect.Value, dl deepLoc) error {
 	if actVal.Uint() != expVal.Uint() {
-		return DiffValErr{
-			dl:  dl,
-			msg: "uintptr pointers differ",
-		}
+		return dl.mkErr(actVal, expVal, "uintptr pointers differ")
 	}
 
 	return nil
@@ -381,10 +863,8 @@ func diffValsUintptr(actVal, expVal reflect.Value, dl deepLoc) error {
 // diffValsFunc returns an error if the two func values differ
 func diffValsFunc(actVal, expVal reflect.Value, dl deepLoc) error {
 	if actVal.Pointer() != expVal.Pointer() {
-		return DiffValErr{
-			dl:  dl,
-			msg: "funcs differ. Actual instance is not equal to expected",
-		}
+		return dl.mkErr(actVal, expVal,
+			"funcs differ. Actual instance is not equal to expected")
 	}
 
 	return nil
@@ -393,23 +873,25 @@ func diffValsFunc(actVal, expVal reflect.Value, dl deepLoc) error {
 // diffValsChan returns an error if the two chan values differ
 func diffValsChan(actVal, expVal reflect.Value, dl deepLoc) error {
 	if actVal.Pointer() != expVal.Pointer() {
-		return DiffValErr{
-			dl:  dl,
-			msg: "chans differ. Actual instance is not equal to expected",
-		}
+		return dl.mkErr(actVal, expVal,
+			"chans differ. Actual instance is not equal to expected")
 	}
 
 	return nil
 }
 
+// valsDifferMsg returns a message saying that the values differ, showing
+// the values as they are shown by the DefaultValPrinter
+func valsDifferMsg(what string, actVal, expVal reflect.Value) string {
+	return what + " differ. Actual: " + prettyPrintVal(actVal) +
+		", expected: " + prettyPrintVal(expVal)
+}
+
 // diffValsBool returns an error if the two bool values differ
 func diffValsBool(actVal, expVal reflect.Value, dl deepLoc) error {
 	if actVal.Bool() != expVal.Bool() {
-		return DiffValErr{
-			dl: dl,
-			msg: fmt.Sprintf("bool values differ. Actual: %t, expected: %t",
-				actVal.Bool(), expVal.Bool()),
-		}
+		return dl.mkErr(actVal, expVal,
+			valsDifferMsg("bool values", actVal, expVal))
 	}
 
 	return nil
@@ -417,25 +899,26 @@ func diffValsBool(actVal, expVal reflect.Value, dl deepLoc) error {
 
 // diffValsString returns an error if the two string values differ
 func diffValsString(actVal, expVal reflect.Value, dl deepLoc) error {
-	if actVal.String() != expVal.String() {
-		return DiffValErr{
-			dl: dl,
-			msg: fmt.Sprintf("strings differ. Actual: %q, expected: %q",
-				actVal.String(), expVal.String()),
-		}
+	if actVal.String() == expVal.String() {
+		return nil
 	}
 
-	return nil
+	if isMultiLine(actVal.String(), expVal.String()) {
+		return dl.mkErr(actVal, expVal,
+			"strings differ (-expected +actual):\n"+
+				UnifiedDiff(actVal.String(), expVal.String(),
+					dl.cfg.contextLines()))
+	}
+
+	return dl.mkErr(actVal, expVal,
+		valsDifferMsg("strings", actVal, expVal))
 }
 
 // diffValsInt returns an error if the two int values differ
 func diffValsInt(actVal, expVal reflect.Value, dl deepLoc) error {
 	if actVal.Int() != expVal.Int() {
-		return DiffValErr{
-			dl: dl,
-			msg: fmt.Sprintf("int values differ. Actual: %d, expected: %d",
-				actVal.Int(), expVal.Int()),
-		}
+		return dl.mkErr(actVal, expVal,
+			valsDifferMsg("int values", actVal, expVal))
 	}
 
 	return nil
@@ -444,37 +927,48 @@ func diffValsInt(actVal, expVal reflect.Value, dl deepLoc) error {
 // diffValsUint returns an error if the two uint values differ
 func diffValsUint(actVal, expVal reflect.Value, dl deepLoc) error {
 	if actVal.Uint() != expVal.Uint() {
-		return DiffValErr{
-			dl: dl,
-			msg: fmt.Sprintf("uint values differ. Actual: %d, expected: %d",
-				actVal.Uint(), expVal.Uint()),
-		}
+		return dl.mkErr(actVal, expVal,
+			valsDifferMsg("uint values", actVal, expVal))
 	}
 
 	return nil
 }
 
-// diffValsFloat returns an error if the two float values differ
+// tolDesc returns a string describing the tolerances used, if any
+func tolDesc(ft FloatTol) string {
+	if ft.IsZero() {
+		return ""
+	}
+
+	return " (tolerance: " + ft.String() + ")"
+}
+
+// diffValsFloat returns an error if the two float values differ by more
+// than the tolerance allowed
 func diffValsFloat(actVal, expVal reflect.Value, dl deepLoc) error {
-	if actVal.Float() != expVal.Float() {
-		return DiffValErr{
-			dl: dl,
-			msg: fmt.Sprintf("float values differ. Actual: %g, expected: %g",
-				actVal.Float(), expVal.Float()),
-		}
+	ft := dl.cfg.floatTolAt(dl.path)
+
+	if !ft.equalBits(actVal.Float(), expVal.Float(), actVal.Type().Bits()) {
+		return dl.mkErr(actVal, expVal,
+			valsDifferMsg("float values", actVal, expVal)+tolDesc(ft))
 	}
 
 	return nil
 }
 
-// diffValsComplex returns an error if the two complex values differ
+// diffValsComplex returns an error if the two complex values differ by more
+// than the tolerance allowed. The real and imaginary parts are compared
+// separately.
 func diffValsComplex(actVal, expVal reflect.Value, dl deepLoc) error {
-	if actVal.Complex() != expVal.Complex() {
-		return DiffValErr{
-			dl: dl,
-			msg: fmt.Sprintf("complex values differ. Actual: %g, expected: %g",
-				actVal.Complex(), expVal.Complex()),
-		}
+	ft := dl.cfg.floatTolAt(dl.path)
+	actC := actVal.Complex()
+	expC := expVal.Complex()
+	bits := actVal.Type().Bits() / 2 //nolint:mnd
+
+	if !ft.equalBits(real(actC), real(expC), bits) ||
+		!ft.equalBits(imag(actC), imag(expC), bits) {
+		return dl.mkErr(actVal, expVal,
+			valsDifferMsg("complex values", actVal, expVal)+tolDesc(ft))
 	}
 
 	return nil
@@ -0,0 +1,444 @@
+package testhelper
+
+import (
+	"reflect"
+	"slices"
+)
+
+// DiffValsCfg holds configuration details which control how values are
+// compared by the DiffVals method. It allows you to build up a
+// description of the comparison once and reuse it across many tests. The
+// zero value compares values in the same way as the DiffVals func.
+//
+//	ReportAll, if set, causes the comparison to continue after the first
+//	difference is found and all the differences to be reported (as for
+//	the DiffValsAll func)
+//
+//	FloatTol gives the tolerances used when comparing floating point
+//	values (including the real and imaginary parts of complex
+//	values). This can be overridden for particular locations with the
+//	AddFloatTol method. Note that two NaN values only compare as equal
+//	if the NaNsEqual field of the FloatTol is set.
+//
+//	UseEqualMethods, if set, causes values of any type having an Equal
+//	method to be compared using that method rather than by comparing
+//	their contents. The method must take a single parameter of the same
+//	type as the value and return a bool. For instance, the time.Time
+//	type has such a method. A comparator func added through the
+//	AddComparator func takes precedence over the Equal method.
+//
+//	UseSliceEdits, if set, causes slices to be compared by matching their
+//	elements (see the SliceEdits func) rather than index by index. An
+//	element inserted into or deleted from the middle of a slice is then
+//	reported as an unexpected or missing element rather than the slice
+//	lengths being reported as different. Differences in changed elements
+//	are reported at their index in the actual slice.
+//
+//	NilPolicy controls whether nil slices, maps and pointers are treated as
+//	equal to empty or zero values. See the NilPolicy type for details.
+//
+//	IgnoreUnexported, if set, causes the unexported fields of all structs
+//	to be skipped. Only the exported fields are compared. The unexported
+//	fields of particular types can be skipped using the
+//	AddIgnoreUnexported func.
+//
+//	UnexportedPkgs, if not empty, restricts the comparison of unexported
+//	fields to structs whose types are defined in one of the listed
+//	packages (given by their import paths). The unexported fields of
+//	other structs are skipped. This allows you to compare the internal
+//	details of your own types without depending on those of third-party
+//	types.
+//
+//	ContextLines gives the number of unchanged lines shown before and
+//	after each group of changed lines when multi-line strings differ. If
+//	it is zero then DiffContextLines lines are shown; set it to a
+//	negative value to show no unchanged lines.
+//
+//	CompareBigByValue, if set, causes the math/big number types, *big.Int,
+//	*big.Float and *big.Rat, to be compared by value using their Cmp
+//	method rather than by comparing their contents. Values which are
+//	equal but are held differently (for instance, *big.Float values with
+//	different precisions) then compare as equal. A comparator func added
+//	through the AddComparator func takes precedence.
+type DiffValsCfg struct {
+	ReportAll         bool
+	FloatTol          FloatTol
+	UseEqualMethods   bool
+	UseSliceEdits     bool
+	NilPolicy         NilPolicy
+	IgnoreUnexported  bool
+	UnexportedPkgs    []string
+	ContextLines      int
+	CompareBigByValue bool
+
+	ignore           []PathPattern
+	floatTols        []pathFloatTol
+	comparators      []typeComparator
+	unordered        []PathPattern
+	transformers     []transformer
+	ignoreUnexported []reflect.Type
+}
+
+// transformer associates a transformer func with the type of value it
+// transforms and the locations where it applies. If there are no
+// PathPatterns it applies at every location.
+type transformer struct {
+	name   string
+	t      reflect.Type
+	pps    []PathPattern
+	trFunc func(v reflect.Value) reflect.Value
+}
+
+// typeComparator associates a comparator func with the type of value it
+// compares
+type typeComparator struct {
+	t       reflect.Type
+	cmpFunc func(act, exp reflect.Value) bool
+}
+
+// pathFloatTol associates a FloatTol with the locations where it applies
+type pathFloatTol struct {
+	pps []PathPattern
+	ft  FloatTol
+}
+
+// AddIgnore parses the patterns and adds them to the collection of
+// locations which will not be compared. See the PathPattern type for a
+// description of the syntax. If any of the patterns cannot be parsed an
+// error is returned and none of the patterns are added.
+func (dvc *DiffValsCfg) AddIgnore(patterns ...string) error {
+	pps, err := parsePathPatterns(patterns)
+	if err != nil {
+		return err
+	}
+
+	dvc.ignore = append(dvc.ignore, pps...)
+
+	return nil
+}
+
+// AddUnordered parses the patterns and records that any slices or arrays
+// at the locations they match should be compared without regard to the
+// order of their elements. The elements are treated as a multiset; each
+// element of the actual value must be matched by a distinct, equal element
+// of the expected value. Any unmatched elements are reported as either
+// unexpected (present in the actual value but not the expected) or
+// missing (present in the expected value but not the actual). See the
+// PathPattern type for a description of the syntax. If any of the patterns
+// cannot be parsed an error is returned and none of the patterns are
+// added.
+//
+// Note that the comparison takes time proportional to the product of the
+// lengths of the values.
+func (dvc *DiffValsCfg) AddUnordered(patterns ...string) error {
+	pps, err := parsePathPatterns(patterns)
+	if err != nil {
+		return err
+	}
+
+	dvc.unordered = append(dvc.unordered, pps...)
+
+	return nil
+}
+
+// AddFloatTol parses the patterns and records that the tolerances should be
+// used when comparing floating point values at any of the locations they
+// match. See the PathPattern type for a description of the syntax. If more
+// than one call matches a location then the tolerances from the first
+// matching call are used. If any of the patterns cannot be parsed an error
+// is returned and the tolerances are not added.
+func (dvc *DiffValsCfg) AddFloatTol(ft FloatTol, patterns ...string) error {
+	pps, err := parsePathPatterns(patterns)
+	if err != nil {
+		return err
+	}
+
+	dvc.floatTols = append(dvc.floatTols, pathFloatTol{pps: pps, ft: ft})
+
+	return nil
+}
+
+// AddComparator records a func to be used to compare values of type T. The
+// func should return true if the values are equal and false
+// otherwise. Whenever values of type T are found while comparing values
+// with the DiffVals method, the comparator will be called instead of
+// comparing their contents. Any previously added comparator for the same
+// type is replaced.
+//
+// This is useful for types having internal details which may differ
+// between values which are semantically equal.
+//
+// Note that values held in unexported struct fields can only be passed to
+// the comparator if they can be accessed; this is normally the case but
+// if not the values will be compared by content as usual.
+func AddComparator[T any](dvc *DiffValsCfg, cmp func(act, exp T) bool) {
+	t := reflect.TypeFor[T]()
+	tc := typeComparator{
+		t: t,
+		cmpFunc: func(act, exp reflect.Value) bool {
+			actT, _ := act.Interface().(T)
+			expT, _ := exp.Interface().(T)
+
+			return cmp(actT, expT)
+		},
+	}
+
+	for i, c := range dvc.comparators {
+		if c.t == t {
+			dvc.comparators[i] = tc
+			return
+		}
+	}
+
+	dvc.comparators = append(dvc.comparators, tc)
+}
+
+// AddTransformer records a func to be applied to values of type T before
+// they are compared. Whenever values of type T are found while comparing
+// values with the DiffVals method, the func is applied to both the actual
+// and the expected values and the results are compared instead. If a
+// difference is found in the transformed values, the name of the
+// transformer is shown in braces in the Path to the difference. Each
+// transformer should have a distinct name.
+//
+// This is useful for normalising values before comparing them; for
+// instance, sorting slices, changing the case of strings or rounding
+// times.
+//
+// If more than one transformer applies to a value they are all applied in
+// the order in which they were added. A transformer is not applied again
+// to its own results (though it will be applied to any values of type T
+// found within them). Note that the transformer should not change the
+// values passed to it as these are the values being compared.
+func AddTransformer[T any](dvc *DiffValsCfg, name string, f func(T) T) {
+	dvc.transformers = append(dvc.transformers, mkTransformer(name, f, nil))
+}
+
+// AddPathTransformer records a func to be applied to values of type T
+// before they are compared in the same way as AddTransformer but only at
+// the locations matched by the patterns. See the PathPattern type for a
+// description of the syntax. If any of the patterns cannot be parsed an
+// error is returned and the transformer is not added.
+func AddPathTransformer[T any](dvc *DiffValsCfg, name string, f func(T) T,
+	patterns ...string,
+) error {
+	pps, err := parsePathPatterns(patterns)
+	if err != nil {
+		return err
+	}
+
+	dvc.transformers = append(dvc.transformers, mkTransformer(name, f, pps))
+
+	return nil
+}
+
+// mkTransformer returns a transformer applying the func to values of type
+// T at the locations matched by the PathPatterns
+func mkTransformer[T any](name string, f func(T) T, pps []PathPattern,
+) transformer {
+	return transformer{
+		name: name,
+		t:    reflect.TypeFor[T](),
+		pps:  pps,
+		trFunc: func(v reflect.Value) reflect.Value {
+			vT, _ := v.Interface().(T)
+			result := f(vT)
+
+			// take the address so that the result has type T even if T
+			// is an interface type
+			return reflect.ValueOf(&result).Elem()
+		},
+	}
+}
+
+// transformerFor returns the first transformer to be applied to values of
+// the given type at the given location. A transformer is not returned if it
+// has already been applied at this location. If there is no transformer to
+// apply it returns false.
+func (dvc DiffValsCfg) transformerFor(t reflect.Type, p Path,
+) (transformer, bool) {
+	for _, tr := range dvc.transformers {
+		if tr.t != t {
+			continue
+		}
+
+		if tr.pps != nil && !matchesAny(tr.pps, p) {
+			continue
+		}
+
+		if !appliedAt(tr.name, p) {
+			return tr, true
+		}
+	}
+
+	return transformer{}, false
+}
+
+// appliedAt returns true if the named transformer is among the
+// transformations at the end of the Path
+func appliedAt(name string, p Path) bool {
+	for i := len(p) - 1; i >= 0 && p[i].Kind == PathTransform; i-- {
+		if p[i].Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AddIgnoreUnexported records that the unexported fields of structs of type
+// T should not be compared. Only the exported fields are compared.
+func AddIgnoreUnexported[T any](dvc *DiffValsCfg) {
+	t := reflect.TypeFor[T]()
+	if !slices.Contains(dvc.ignoreUnexported, t) {
+		dvc.ignoreUnexported = append(dvc.ignoreUnexported, t)
+	}
+}
+
+// compareField returns true if the field of the struct type should be
+// compared.
+func (dvc DiffValsCfg) compareField(t reflect.Type, f reflect.StructField,
+) bool {
+	if f.IsExported() {
+		return true
+	}
+
+	if dvc.IgnoreUnexported || slices.Contains(dvc.ignoreUnexported, t) {
+		return false
+	}
+
+	return len(dvc.UnexportedPkgs) == 0 ||
+		slices.Contains(dvc.UnexportedPkgs, t.PkgPath())
+}
+
+// comparatorFor returns the func to use to compare the values (which are
+// of the same type) and a description of the func. If the
+// CompareBigByValue flag is set, the math/big number types are compared by
+// value unless a comparator has been added for them. If there is no
+// comparator func to use it returns nil.
+func (dvc DiffValsCfg) comparatorFor(actVal, expVal reflect.Value,
+) (func(act, exp reflect.Value) bool, string) {
+	t := actVal.Type()
+
+	for _, c := range dvc.comparators {
+		if c.t == t {
+			return c.cmpFunc, "the comparator for " + t.String()
+		}
+	}
+
+	if t.Kind() == reflect.Ptr && (actVal.IsNil() || expVal.IsNil()) {
+		return nil, ""
+	}
+
+	if dvc.CompareBigByValue {
+		if cmpFunc := bigCmpFunc(t); cmpFunc != nil {
+			return cmpFunc, "the Cmp method"
+		}
+	}
+
+	if !dvc.UseEqualMethods || !hasEqualMethod(t) {
+		return nil, ""
+	}
+
+	return func(act, exp reflect.Value) bool {
+		return act.MethodByName("Equal").
+			Call([]reflect.Value{exp})[0].Bool()
+	}, "the Equal method"
+}
+
+// hasEqualMethod returns true if the type has an Equal method taking a
+// single argument of the same type and returning a bool
+func hasEqualMethod(t reflect.Type) bool {
+	if t.Kind() == reflect.Interface {
+		return false
+	}
+
+	m, ok := t.MethodByName("Equal")
+	if !ok {
+		return false
+	}
+
+	mt := m.Type
+
+	return mt.NumIn() == 2 && mt.In(1) == t &&
+		mt.NumOut() == 1 && mt.Out(0).Kind() == reflect.Bool
+}
+
+// contextLines returns the number of lines of context to show around the
+// differences between multi-line strings
+func (dvc DiffValsCfg) contextLines() int {
+	if dvc.ContextLines == 0 {
+		return DiffContextLines
+	}
+
+	return dvc.ContextLines
+}
+
+// floatTolAt returns the tolerances to use at the given location
+func (dvc DiffValsCfg) floatTolAt(p Path) FloatTol {
+	for _, pft := range dvc.floatTols {
+		if matchesAny(pft.pps, p) {
+			return pft.ft
+		}
+	}
+
+	return dvc.FloatTol
+}
+
+// DiffVals compares the actual and expected values and returns an error if
+// they are different. The comparison is controlled by the DiffValsCfg. If
+// ReportAll is set the error will be a DiffValErrs, otherwise it will be a
+// DiffValErr.
+func (dvc DiffValsCfg) DiffVals(actVal, expVal any) error {
+	return dvc.diffVals(actVal, expVal, nil)
+}
+
+// diffVals compares the actual and expected values using the configuration
+// given by the DiffValsCfg and the additional locations to skip.
+func (dvc DiffValsCfg) diffVals(actVal, expVal any, skipLocs [][]string,
+) error {
+	dl := makeDeepLoc(&dvc, skipLocs)
+	if !dvc.ReportAll {
+		return diffValsTop(actVal, expVal, dl)
+	}
+
+	dl.found = &DiffValErrs{}
+
+	if err := dl.record(diffValsTop(actVal, expVal, dl)); err != nil {
+		return err
+	}
+
+	if dl.found.Count > 0 {
+		return *dl.found
+	}
+
+	return nil
+}
+
+// parsePathPatterns parses each of the patterns and returns the resulting
+// PathPatterns or the first error found.
+func parsePathPatterns(patterns []string) ([]PathPattern, error) {
+	pps := make([]PathPattern, 0, len(patterns))
+
+	for _, p := range patterns {
+		pp, err := ParsePathPattern(p)
+		if err != nil {
+			return nil, err
+		}
+
+		pps = append(pps, pp)
+	}
+
+	return pps, nil
+}
+
+// matchesAny returns true if any of the PathPatterns matches the Path
+func matchesAny(pps []PathPattern, p Path) bool {
+	for _, pp := range pps {
+		if pp.Matches(p) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,905 @@
+package testhelper_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+// cfgTestItem exists only to test the behaviour of DiffValsCfg comparisons
+type cfgTestItem struct {
+	ID        int
+	Name      string
+	UpdatedAt int
+}
+
+// cfgTestRecord exists only to test the behaviour of DiffValsCfg
+// comparisons
+type cfgTestRecord struct {
+	ID    int
+	Items []cfgTestItem
+	Meta  map[string]string
+}
+
+func TestDiffValsCfgIgnore(t *testing.T) {
+	actVal := cfgTestRecord{
+		ID: 1,
+		Items: []cfgTestItem{
+			{ID: 10, Name: "a", UpdatedAt: 100},
+			{ID: 11, Name: "b", UpdatedAt: 101},
+		},
+		Meta: map[string]string{"requestID": "r1", "user": "u"},
+	}
+	expVal := cfgTestRecord{
+		ID: 2,
+		Items: []cfgTestItem{
+			{ID: 20, Name: "a", UpdatedAt: 200},
+			{ID: 21, Name: "b", UpdatedAt: 201},
+		},
+		Meta: map[string]string{"requestID": "r2", "user": "u"},
+	}
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		ignore    []string
+		reportAll bool
+		cfgErr    testhelper.ExpErr
+	}{
+		{
+			ID:     testhelper.MkID("no ignores"),
+			ExpErr: testhelper.MkExpErr("this.ID: int values differ."),
+		},
+		{
+			ID:     testhelper.MkID("ignore IDs at any depth"),
+			ignore: []string{"**.ID"},
+			ExpErr: testhelper.MkExpErr(
+				"this.Items[0].UpdatedAt: int values differ."),
+		},
+		{
+			ID:     testhelper.MkID("ignore IDs and UpdatedAt"),
+			ignore: []string{"**.ID", "Items[*].UpdatedAt"},
+			ExpErr: testhelper.MkExpErr(
+				"this.Meta[\"requestID\"]: strings differ."),
+		},
+		{
+			ID: testhelper.MkID("ignore IDs, UpdatedAt and requestID"),
+			ignore: []string{
+				"**.ID", "Items[*].UpdatedAt", `Meta["requestID"]`,
+			},
+		},
+		{
+			ID:        testhelper.MkID("report all, some ignored"),
+			ignore:    []string{"Items", `Meta["requestID"]`},
+			reportAll: true,
+			ExpErr:    testhelper.MkExpErr("this.ID: int values differ."),
+		},
+		{
+			ID:     testhelper.MkID("bad pattern"),
+			ignore: []string{"Items[", "ID"},
+			cfgErr: testhelper.MkExpErr("bad path pattern", `"Items["`),
+			ExpErr: testhelper.MkExpErr("this.ID: int values differ."),
+		},
+	}
+
+	for _, tc := range testCases {
+		dvc := testhelper.DiffValsCfg{ReportAll: tc.reportAll}
+
+		err := dvc.AddIgnore(tc.ignore...)
+		testhelper.CheckExpErrWithID(t, tc.IDStr()+": AddIgnore", err,
+			tc.cfgErr)
+
+		err = dvc.DiffVals(actVal, expVal)
+		testhelper.CheckExpErr(t, err, tc)
+	}
+}
+
+// cfgTestResult exists only to test the behaviour of DiffValsCfg float
+// comparisons
+type cfgTestResult struct {
+	Mean   float64
+	StdDev float32
+	Points map[string]complex128
+}
+
+func TestDiffValsCfgFloatTol(t *testing.T) {
+	actVal := cfgTestResult{
+		Mean:   100.001,
+		StdDev: 1.01,
+		Points: map[string]complex128{"a": complex(1.001, 2.001)},
+	}
+	expVal := cfgTestResult{
+		Mean:   100.0,
+		StdDev: 1.0,
+		Points: map[string]complex128{"a": complex(1, 2)},
+	}
+
+	type pathTol struct {
+		ft       testhelper.FloatTol
+		patterns []string
+	}
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		actVal   any
+		expVal   any
+		ft       testhelper.FloatTol
+		pathTols []pathTol
+	}{
+		{
+			ID:     testhelper.MkID("no tolerance"),
+			actVal: actVal,
+			expVal: expVal,
+			ExpErr: testhelper.MkExpErr("this.Mean: float values differ."),
+		},
+		{
+			ID:     testhelper.MkID("global rel tolerance, too small"),
+			actVal: actVal,
+			expVal: expVal,
+			ft:     testhelper.FloatTol{Rel: 1e-4},
+			ExpErr: testhelper.MkExpErr("this.StdDev: float values differ.",
+				"(tolerance: abs: 0, rel: 0.0001)"),
+		},
+		{
+			ID:     testhelper.MkID("global rel tolerance"),
+			actVal: actVal,
+			expVal: expVal,
+			ft:     testhelper.FloatTol{Rel: 0.01},
+		},
+		{
+			ID:     testhelper.MkID("global and per-path tolerances"),
+			actVal: actVal,
+			expVal: expVal,
+			ft:     testhelper.FloatTol{Abs: 0.01},
+			pathTols: []pathTol{
+				{
+					ft:       testhelper.FloatTol{Abs: 0.0001},
+					patterns: []string{"Points[*]"},
+				},
+			},
+			ExpErr: testhelper.MkExpErr(
+				"this.Points[\"a\"]: complex values differ.",
+				"(tolerance: abs: 0.0001, rel: 0)"),
+		},
+		{
+			ID:     testhelper.MkID("per-path tolerances"),
+			actVal: actVal,
+			expVal: expVal,
+			pathTols: []pathTol{
+				{
+					ft:       testhelper.FloatTol{Abs: 0.01},
+					patterns: []string{"Mean", "StdDev"},
+				},
+				{
+					ft:       testhelper.FloatTol{Abs: 0.002},
+					patterns: []string{"Points[*]"},
+				},
+			},
+		},
+		{
+			ID:     testhelper.MkID("NaNs differ by default"),
+			actVal: []float64{math.NaN()},
+			expVal: []float64{math.NaN()},
+			ExpErr: testhelper.MkExpErr("this[0]: float values differ.",
+				"Actual: math.NaN(), expected: math.NaN()"),
+		},
+		{
+			ID:     testhelper.MkID("NaNs equal"),
+			actVal: []float64{math.NaN()},
+			expVal: []float64{math.NaN()},
+			ft:     testhelper.FloatTol{NaNsEqual: true},
+		},
+		{
+			ID:     testhelper.MkID("NaNs equal, in a complex value"),
+			actVal: complex(math.NaN(), 1),
+			expVal: complex(math.NaN(), 1),
+			ft:     testhelper.FloatTol{NaNsEqual: true},
+		},
+		{
+			ID:     testhelper.MkID("NaN and a number differ"),
+			actVal: []float64{math.NaN()},
+			expVal: []float64{1},
+			ft:     testhelper.FloatTol{Abs: math.Inf(1)},
+			ExpErr: testhelper.MkExpErr("this[0]: float values differ.",
+				"Actual: math.NaN(), expected: 1"),
+		},
+	}
+
+	for _, tc := range testCases {
+		dvc := testhelper.DiffValsCfg{FloatTol: tc.ft}
+		for _, pt := range tc.pathTols {
+			err := dvc.AddFloatTol(pt.ft, pt.patterns...)
+			if err != nil {
+				t.Fatal(tc.IDStr(), ": unexpected error: ", err)
+			}
+		}
+
+		err := dvc.DiffVals(tc.actVal, tc.expVal)
+		testhelper.CheckExpErr(t, err, tc)
+	}
+}
+
+// cfgTestID exists only to test the behaviour of DiffValsCfg comparators
+type cfgTestID struct {
+	val     string
+	ignored int
+}
+
+// Equal returns true if the cfgTestIDs have the same val
+func (id cfgTestID) Equal(other cfgTestID) bool {
+	return id.val == other.val
+}
+
+// cfgTestEvent exists only to test the behaviour of DiffValsCfg
+// comparators
+type cfgTestEvent struct {
+	id   cfgTestID
+	when time.Time
+	tags map[string]any
+}
+
+func TestDiffValsCfgComparators(t *testing.T) {
+	t1 := time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+	t1OtherZone := t1.In(time.FixedZone("UTC+1", 3600))
+	t2 := t1.Add(time.Second)
+
+	ev := func(id string, ignored int, when time.Time) cfgTestEvent {
+		return cfgTestEvent{
+			id:   cfgTestID{val: id, ignored: ignored},
+			when: when,
+			tags: map[string]any{"when": when},
+		}
+	}
+
+	caseInsensitive := func(dvc *testhelper.DiffValsCfg) {
+		testhelper.AddComparator(dvc, func(act, exp cfgTestID) bool {
+			return strings.EqualFold(act.val, exp.val)
+		})
+	}
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		actVal    any
+		expVal    any
+		useEqual  bool
+		setupFunc func(*testhelper.DiffValsCfg)
+	}{
+		{
+			ID:     testhelper.MkID("no comparators, equal"),
+			actVal: ev("a", 1, t1),
+			expVal: ev("a", 1, t1),
+		},
+		{
+			ID:     testhelper.MkID("no comparators, same time, other zone"),
+			actVal: ev("a", 1, t1),
+			expVal: ev("a", 1, t1OtherZone),
+			ExpErr: testhelper.MkExpErr("this.when"),
+		},
+		{
+			ID:       testhelper.MkID("Equal methods, same time, other zone"),
+			actVal:   ev("a", 1, t1),
+			expVal:   ev("a", 2, t1OtherZone),
+			useEqual: true,
+		},
+		{
+			ID:       testhelper.MkID("Equal methods, different times"),
+			actVal:   ev("a", 1, t1),
+			expVal:   ev("a", 1, t2),
+			useEqual: true,
+			ExpErr: testhelper.MkExpErr(
+				"this.when: time.Time values differ",
+				"(compared using the Equal method)"),
+		},
+		{
+			ID:       testhelper.MkID("Equal methods, different ids"),
+			actVal:   ev("a", 1, t1),
+			expVal:   ev("A", 1, t1),
+			useEqual: true,
+			ExpErr: testhelper.MkExpErr(
+				"this.id: testhelper_test.cfgTestID values differ",
+				"(compared using the Equal method)"),
+		},
+		{
+			ID:        testhelper.MkID("comparator takes precedence"),
+			actVal:    ev("a", 1, t1),
+			expVal:    ev("A", 1, t1),
+			useEqual:  true,
+			setupFunc: caseInsensitive,
+		},
+		{
+			ID:        testhelper.MkID("comparator, differences"),
+			actVal:    ev("a", 1, t1),
+			expVal:    ev("b", 1, t1),
+			setupFunc: caseInsensitive,
+			ExpErr: testhelper.MkExpErr(
+				"this.id: testhelper_test.cfgTestID values differ",
+				"(compared using the comparator for"+
+					" testhelper_test.cfgTestID)"),
+		},
+		{
+			ID:       testhelper.MkID("Equal methods, through pointers"),
+			actVal:   []*time.Time{nil, &t1},
+			expVal:   []*time.Time{nil, &t1OtherZone},
+			useEqual: true,
+		},
+		{
+			ID:       testhelper.MkID("Equal methods, nil pointer"),
+			actVal:   []*time.Time{nil, &t1},
+			expVal:   []*time.Time{&t1, &t1OtherZone},
+			useEqual: true,
+			ExpErr: testhelper.MkExpErr("this[0]:",
+				"the actual value is invalid, the expected value is not"),
+		},
+		{
+			ID:     testhelper.MkID("interface comparator"),
+			actVal: []fmt.Stringer{t1, t1OtherZone},
+			expVal: []fmt.Stringer{t1OtherZone, nil},
+			setupFunc: func(dvc *testhelper.DiffValsCfg) {
+				testhelper.AddComparator(dvc,
+					func(act, exp fmt.Stringer) bool {
+						return act != nil && exp != nil
+					})
+			},
+			ExpErr: testhelper.MkExpErr(
+				"this[1]: fmt.Stringer values differ"),
+		},
+	}
+
+	for _, tc := range testCases {
+		dvc := testhelper.DiffValsCfg{UseEqualMethods: tc.useEqual}
+		if tc.setupFunc != nil {
+			tc.setupFunc(&dvc)
+		}
+
+		err := dvc.DiffVals(tc.actVal, tc.expVal)
+		testhelper.CheckExpErr(t, err, tc)
+	}
+}
+
+func TestDiffValsCfgUnordered(t *testing.T) {
+	actRec := cfgTestRecord{
+		Items: []cfgTestItem{
+			{ID: 2, Name: "b"},
+			{ID: 1, Name: "a"},
+			{ID: 3, Name: "c", UpdatedAt: 99},
+		},
+	}
+	expRec := cfgTestRecord{
+		Items: []cfgTestItem{
+			{ID: 1, Name: "a"},
+			{ID: 2, Name: "b"},
+			{ID: 3, Name: "c"},
+		},
+	}
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		actVal    any
+		expVal    any
+		unordered []string
+		ignore    []string
+		reportAll bool
+	}{
+		{
+			ID:     testhelper.MkID("ordered, differs"),
+			actVal: []int{1, 2, 3},
+			expVal: []int{3, 2, 1},
+			ExpErr: testhelper.MkExpErr("this[0]: int values differ."),
+		},
+		{
+			ID:        testhelper.MkID("unordered, same"),
+			actVal:    []int{1, 2, 3, 2},
+			expVal:    []int{2, 3, 2, 1},
+			unordered: []string{"**"},
+		},
+		{
+			ID:        testhelper.MkID("unordered array, same"),
+			actVal:    [...]string{"a", "b"},
+			expVal:    [...]string{"b", "a"},
+			unordered: []string{"**"},
+		},
+		{
+			ID:        testhelper.MkID("unordered, differs"),
+			actVal:    []int{1, 2, 4, 2, 5},
+			expVal:    []int{2, 3, 2, 1},
+			unordered: []string{"**"},
+			reportAll: true,
+			ExpErr: testhelper.MkExpErr(
+				"this: unexpected element, not in the expected values." +
+					" Actual[2]: 4\n" +
+					"this: unexpected element, not in the expected values." +
+					" Actual[4]: 5\n" +
+					"this: missing element, not in the actual values." +
+					" Expected[1]: 3"),
+		},
+		{
+			ID:        testhelper.MkID("unordered struct slice, differs"),
+			actVal:    actRec,
+			expVal:    expRec,
+			unordered: []string{"Items"},
+			ExpErr: testhelper.MkExpErr(
+				"this.Items: unexpected element",
+				"Actual[2]: testhelper_test.cfgTestItem"+
+					`{ID: 3, Name: "c", UpdatedAt: 99}`),
+		},
+		{
+			ID:        testhelper.MkID("unordered struct slice, ignored field"),
+			actVal:    actRec,
+			expVal:    expRec,
+			unordered: []string{"Items"},
+			ignore:    []string{"Items[*].UpdatedAt"},
+		},
+	}
+
+	for _, tc := range testCases {
+		dvc := testhelper.DiffValsCfg{ReportAll: tc.reportAll}
+		if err := dvc.AddUnordered(tc.unordered...); err != nil {
+			t.Fatal(tc.IDStr(), ": unexpected error: ", err)
+		}
+
+		if err := dvc.AddIgnore(tc.ignore...); err != nil {
+			t.Fatal(tc.IDStr(), ": unexpected error: ", err)
+		}
+
+		err := dvc.DiffVals(tc.actVal, tc.expVal)
+		testhelper.CheckExpErr(t, err, tc)
+	}
+}
+
+func TestDiffValsCfgSliceEdits(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		actVal    any
+		expVal    any
+		reportAll bool
+	}{
+		{
+			ID:     testhelper.MkID("same"),
+			actVal: []int{1, 2, 3},
+			expVal: []int{1, 2, 3},
+		},
+		{
+			ID:        testhelper.MkID("insertion"),
+			actVal:    []int{1, 9, 2, 3},
+			expVal:    []int{1, 2, 3},
+			reportAll: true,
+			ExpErr: testhelper.MkExpErr(
+				"this: unexpected element, not in the expected values." +
+					" Actual[1]: 9"),
+		},
+		{
+			ID:        testhelper.MkID("deletion"),
+			actVal:    []string{"a", "c"},
+			expVal:    []string{"a", "b", "c"},
+			reportAll: true,
+			ExpErr: testhelper.MkExpErr(
+				"this: missing element, not in the actual values." +
+					" Expected[1]: \"b\""),
+		},
+		{
+			ID:        testhelper.MkID("change and deletion"),
+			actVal:    []int{1, 5, 4},
+			expVal:    []int{1, 2, 3, 4},
+			reportAll: true,
+			ExpErr: testhelper.MkExpErr(
+				"this[1]: int values differ. Actual: 5, expected: 2\n" +
+					"this: missing element, not in the actual values." +
+					" Expected[2]: 3"),
+		},
+		{
+			ID: testhelper.MkID("nested change"),
+			actVal: []cfgTestItem{
+				{ID: 1, Name: "a"},
+				{ID: 2, Name: "x"},
+			},
+			expVal: []cfgTestItem{
+				{ID: 1, Name: "a"},
+				{ID: 2, Name: "b"},
+			},
+			ExpErr: testhelper.MkExpErr("this[1].Name: strings differ."),
+		},
+	}
+
+	for _, tc := range testCases {
+		dvc := testhelper.DiffValsCfg{
+			ReportAll:     tc.reportAll,
+			UseSliceEdits: true,
+		}
+
+		err := dvc.DiffVals(tc.actVal, tc.expVal)
+		testhelper.CheckExpErr(t, err, tc)
+	}
+}
+
+func TestDiffValsCfgNilPolicy(t *testing.T) {
+	type rec struct {
+		S []int
+		M map[string]int
+		P *cfgTestItem
+	}
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		actVal    any
+		expVal    any
+		nilPolicy testhelper.NilPolicy
+	}{
+		{
+			ID:        testhelper.MkID("NilEqualsEmpty, nil vs empty"),
+			actVal:    rec{},
+			expVal:    rec{S: []int{}, M: map[string]int{}},
+			nilPolicy: testhelper.NilEqualsEmpty,
+		},
+		{
+			ID:        testhelper.MkID("NilEqualsEmpty, nil vs zero pointer"),
+			actVal:    rec{},
+			expVal:    rec{P: &cfgTestItem{}},
+			nilPolicy: testhelper.NilEqualsEmpty,
+			ExpErr: testhelper.MkExpErr(
+				"this.P: the actual value is invalid"),
+		},
+		{
+			ID:        testhelper.MkID("NilDiffersFromEmpty, nil slice"),
+			actVal:    rec{M: map[string]int{}},
+			expVal:    rec{S: []int{}, M: map[string]int{}},
+			nilPolicy: testhelper.NilDiffersFromEmpty,
+			ExpErr: testhelper.MkExpErr(
+				"this.S: the actual value is a nil slice," +
+					" the expected value is not"),
+		},
+		{
+			ID:        testhelper.MkID("NilDiffersFromEmpty, nil map"),
+			actVal:    rec{S: []int{}, M: map[string]int{}},
+			expVal:    rec{S: []int{}},
+			nilPolicy: testhelper.NilDiffersFromEmpty,
+			ExpErr: testhelper.MkExpErr(
+				"this.M: the expected value is a nil map," +
+					" the actual value is not"),
+		},
+		{
+			ID:        testhelper.MkID("NilDiffersFromEmpty, both nil"),
+			actVal:    rec{},
+			expVal:    rec{},
+			nilPolicy: testhelper.NilDiffersFromEmpty,
+		},
+		{
+			ID:        testhelper.MkID("NilEqualsZero, nil vs zero pointer"),
+			actVal:    rec{S: []int{}},
+			expVal:    rec{M: map[string]int{}, P: &cfgTestItem{}},
+			nilPolicy: testhelper.NilEqualsZero,
+		},
+		{
+			ID:        testhelper.MkID("NilEqualsZero, non-zero pointer"),
+			actVal:    rec{P: &cfgTestItem{Name: "a"}},
+			expVal:    rec{},
+			nilPolicy: testhelper.NilEqualsZero,
+			ExpErr: testhelper.MkExpErr(
+				"this.P.Name: strings differ."),
+		},
+	}
+
+	for _, tc := range testCases {
+		dvc := testhelper.DiffValsCfg{NilPolicy: tc.nilPolicy}
+
+		err := dvc.DiffVals(tc.actVal, tc.expVal)
+		testhelper.CheckExpErr(t, err, tc)
+	}
+}
+
+func TestDiffValsCfgTransformers(t *testing.T) {
+	sorted := func(s []int) []int { return slices.Sorted(slices.Values(s)) }
+	second := func(tm time.Time) time.Time { return tm.Truncate(time.Second) }
+	noID := func(item cfgTestItem) cfgTestItem {
+		item.ID = 0
+		return item
+	}
+
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		actVal any
+		expVal any
+		addTr  func(dvc *testhelper.DiffValsCfg) error
+		cfgErr testhelper.ExpErr
+	}{
+		{
+			ID:     testhelper.MkID("no transformer"),
+			actVal: []int{3, 1, 2},
+			expVal: []int{1, 2, 3},
+			addTr:  func(_ *testhelper.DiffValsCfg) error { return nil },
+			ExpErr: testhelper.MkExpErr("this[0]: int values differ."),
+		},
+		{
+			ID:     testhelper.MkID("sorted, same"),
+			actVal: []int{3, 1, 2},
+			expVal: []int{1, 2, 3},
+			addTr: func(dvc *testhelper.DiffValsCfg) error {
+				testhelper.AddTransformer(dvc, "sorted", sorted)
+				return nil
+			},
+		},
+		{
+			ID:     testhelper.MkID("sorted, differs"),
+			actVal: map[string][]int{"a": {3, 1}},
+			expVal: map[string][]int{"a": {2, 1}},
+			addTr: func(dvc *testhelper.DiffValsCfg) error {
+				testhelper.AddTransformer(dvc, "sorted", sorted)
+				return nil
+			},
+			ExpErr: testhelper.MkExpErr(
+				"this[\"a\"]{sorted}[1]: int values differ." +
+					" Actual: 3, expected: 2"),
+		},
+		{
+			ID:     testhelper.MkID("truncated times"),
+			actVal: []time.Time{start.Add(time.Millisecond)},
+			expVal: []time.Time{start.Add(time.Microsecond)},
+			addTr: func(dvc *testhelper.DiffValsCfg) error {
+				testhelper.AddTransformer(dvc, "second", second)
+				return nil
+			},
+		},
+		{
+			ID: testhelper.MkID("path transformer, chained"),
+			actVal: cfgTestRecord{
+				Meta: map[string]string{"user": " Bob", "id": "X"},
+			},
+			expVal: cfgTestRecord{
+				Meta: map[string]string{"user": "bob ", "id": "x"},
+			},
+			addTr: func(dvc *testhelper.DiffValsCfg) error {
+				err := testhelper.AddPathTransformer(dvc,
+					"trim", strings.TrimSpace, `Meta["user"]`)
+				if err != nil {
+					return err
+				}
+
+				return testhelper.AddPathTransformer(dvc,
+					"lower", strings.ToLower, "Meta[*]")
+			},
+		},
+		{
+			ID: testhelper.MkID("path transformer, differs"),
+			actVal: cfgTestRecord{
+				Meta: map[string]string{"user": "Bob", "id": "X"},
+			},
+			expVal: cfgTestRecord{
+				Meta: map[string]string{"user": "bob", "id": "x"},
+			},
+			addTr: func(dvc *testhelper.DiffValsCfg) error {
+				return testhelper.AddPathTransformer(dvc,
+					"lower", strings.ToLower, `Meta["user"]`)
+			},
+			ExpErr: testhelper.MkExpErr("this.Meta[\"id\"]: strings differ."),
+		},
+		{
+			ID: testhelper.MkID("struct transformer, nested difference"),
+			actVal: cfgTestRecord{
+				Items: []cfgTestItem{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}},
+			},
+			expVal: cfgTestRecord{
+				Items: []cfgTestItem{{ID: 9, Name: "a"}, {ID: 8, Name: "c"}},
+			},
+			addTr: func(dvc *testhelper.DiffValsCfg) error {
+				testhelper.AddTransformer(dvc, "noID", noID)
+				return nil
+			},
+			ExpErr: testhelper.MkExpErr(
+				"this.Items[1]{noID}.Name: strings differ."),
+		},
+		{
+			ID:     testhelper.MkID("different type, not transformed"),
+			actVal: []int64{3, 1, 2},
+			expVal: []int64{1, 2, 3},
+			addTr: func(dvc *testhelper.DiffValsCfg) error {
+				testhelper.AddTransformer(dvc, "sorted", sorted)
+				return nil
+			},
+			ExpErr: testhelper.MkExpErr("this[0]: int values differ."),
+		},
+		{
+			ID:     testhelper.MkID("bad pattern"),
+			actVal: "A",
+			expVal: "a",
+			addTr: func(dvc *testhelper.DiffValsCfg) error {
+				return testhelper.AddPathTransformer(dvc,
+					"lower", strings.ToLower, "[")
+			},
+			cfgErr: testhelper.MkExpErr("bad path pattern", `"["`),
+			ExpErr: testhelper.MkExpErr("this: strings differ."),
+		},
+	}
+
+	for _, tc := range testCases {
+		dvc := testhelper.DiffValsCfg{}
+
+		err := tc.addTr(&dvc)
+		testhelper.CheckExpErrWithID(t, tc.IDStr()+": add transformer", err,
+			tc.cfgErr)
+
+		err = dvc.DiffVals(tc.actVal, tc.expVal)
+		testhelper.CheckExpErr(t, err, tc)
+	}
+}
+
+// cfgTestPrivate exists only to test the behaviour of DiffValsCfg
+// comparisons of unexported fields
+type cfgTestPrivate struct {
+	Name    string
+	counter int
+	When    time.Time
+}
+
+func TestDiffValsCfgUnexported(t *testing.T) {
+	thisPkg := reflect.TypeFor[cfgTestPrivate]().PkgPath()
+	when := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	actVal := cfgTestPrivate{Name: "a", counter: 1, When: when}
+	expVal := cfgTestPrivate{Name: "a", counter: 2, When: when.Add(1)}
+	expTimeDiff := cfgTestPrivate{Name: "a", counter: 1, When: when.Add(1)}
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		expVal      cfgTestPrivate
+		dvc         testhelper.DiffValsCfg
+		ignoreTypes func(dvc *testhelper.DiffValsCfg)
+	}{
+		{
+			ID:     testhelper.MkID("unexported fields compared"),
+			expVal: expVal,
+			dvc:    testhelper.DiffValsCfg{ReportAll: true},
+			ExpErr: testhelper.MkExpErr(
+				"this.counter: int values differ. Actual: 1, expected: 2"+
+					" (in the unexported field: counter;",
+				"this.When.wall: uint values differ.",
+				"(in the unexported field: wall;"),
+		},
+		{
+			ID:     testhelper.MkID("all unexported fields ignored"),
+			expVal: expVal,
+			dvc:    testhelper.DiffValsCfg{IgnoreUnexported: true},
+		},
+		{
+			ID:          testhelper.MkID("unexported fields of a type ignored"),
+			expVal:      expTimeDiff,
+			ignoreTypes: testhelper.AddIgnoreUnexported[time.Time],
+		},
+		{
+			ID:     testhelper.MkID("only this package, time differs"),
+			expVal: expTimeDiff,
+			dvc:    testhelper.DiffValsCfg{UnexportedPkgs: []string{thisPkg}},
+		},
+		{
+			ID:     testhelper.MkID("only this package, both differ"),
+			expVal: expVal,
+			dvc:    testhelper.DiffValsCfg{UnexportedPkgs: []string{thisPkg}},
+			ExpErr: testhelper.MkExpErr("this.counter: int values differ."),
+		},
+		{
+			ID:     testhelper.MkID("only the time package, both differ"),
+			expVal: expVal,
+			dvc:    testhelper.DiffValsCfg{UnexportedPkgs: []string{"time"}},
+			ExpErr: testhelper.MkExpErr("this.When.wall: uint values differ."),
+		},
+	}
+
+	for _, tc := range testCases {
+		if tc.ignoreTypes != nil {
+			tc.ignoreTypes(&tc.dvc)
+		}
+
+		err := tc.dvc.DiffVals(actVal, tc.expVal)
+		testhelper.CheckExpErr(t, err, tc)
+	}
+}
+
+// cfgTestReadOnly exists only to test that values reached through
+// unexported fields are not made accessible by DiffVals
+type cfgTestReadOnly struct {
+	n     int
+	when  time.Time
+	tags  map[string]int
+	iface any
+}
+
+func TestDiffValsCfgUnexportedReadOnly(t *testing.T) {
+	when := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	actVal := cfgTestReadOnly{
+		n:     1,
+		when:  when,
+		tags:  map[string]int{"a": 1},
+		iface: when,
+	}
+	expVal := cfgTestReadOnly{
+		n:     2,
+		when:  when.Add(1),
+		tags:  map[string]int{"a": 2},
+		iface: when.Add(1),
+	}
+
+	dvc := testhelper.DiffValsCfg{ReportAll: true, UseEqualMethods: true}
+
+	var dves testhelper.DiffValErrs
+	if !errors.As(dvc.DiffVals(actVal, expVal), &dves) {
+		t.Fatal("a DiffValErrs should have been returned")
+	}
+
+	expPaths := []string{
+		"this.n",
+		"this.when",
+		"this.tags[\"a\"]",
+		"this.iface",
+	}
+	paths := []string{}
+
+	for _, dve := range dves.Errs {
+		paths = append(paths, dve.Path.String())
+
+		for _, v := range []reflect.Value{dve.Act, dve.Exp} {
+			if v.CanInterface() || v.CanSet() {
+				t.Log(dve.Path.String())
+				t.Error("\t: the value should not be accessible")
+			}
+		}
+	}
+
+	if testhelper.DiffStringSlice(t, "test: read-only values", "paths",
+		paths, expPaths) {
+		return
+	}
+
+	for _, i := range []int{1, 3} {
+		testhelper.ShouldContain(t, "test: read-only values",
+			"message for "+expPaths[i], dves.Errs[i].Msg,
+			[]string{"(compared using the Equal method)"})
+	}
+}
+
+func TestDiffValsCfgContextLines(t *testing.T) {
+	actVal := "a\nb\nc\nd\n"
+	expVal := "a\nb\nX\nd\n"
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		dvc testhelper.DiffValsCfg
+	}{
+		{
+			ID: testhelper.MkID("default context"),
+			ExpErr: testhelper.MkExpErr(
+				"@@ -1,4 +1,4 @@\n a\n b\n-X\n+c\n d"),
+		},
+		{
+			ID:  testhelper.MkID("one line of context"),
+			dvc: testhelper.DiffValsCfg{ContextLines: 1},
+			ExpErr: testhelper.MkExpErr(
+				"@@ -2,3 +2,3 @@\n b\n-X\n+c\n d"),
+		},
+		{
+			ID:     testhelper.MkID("no context"),
+			dvc:    testhelper.DiffValsCfg{ContextLines: -1},
+			ExpErr: testhelper.MkExpErr("@@ -3,1 +3,1 @@\n-X\n+c"),
+		},
+	}
+
+	for _, tc := range testCases {
+		err := tc.dvc.DiffVals(actVal, expVal)
+		testhelper.CheckExpErr(t, err, tc)
+	}
+}
@@ -0,0 +1,130 @@
+package testhelper
+
+import (
+	"fmt"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// PathElemKind records the kind of step taken from one value to the next
+// as the DiffVals func descends through the values being compared.
+type PathElemKind int
+
+// These are the different kinds of PathElem
+const (
+	// PathField is a step into a struct field. The PathElem Name holds the
+	// name of the field.
+	PathField PathElemKind = iota
+	// PathIndex is a step into an element of a slice or an array. The
+	// PathElem Index holds the index of the element.
+	PathIndex
+	// PathMapKey is a step into an entry in a map. The PathElem Key holds
+	// the key of the map entry.
+	PathMapKey
+	// PathPtrDeref is a step from a pointer to the value it points to.
+	PathPtrDeref
+	// PathIfaceUnwrap is a step from an interface to the value it holds.
+	PathIfaceUnwrap
+	// PathTransform is a step from a value to the result of applying a
+	// transformer func to it. The PathElem Name holds the name of the
+	// transformer.
+	PathTransform
+)
+
+// String returns a string describing the PathElemKind
+func (pek PathElemKind) String() string {
+	switch pek {
+	case PathField:
+		return "field"
+	case PathIndex:
+		return "index"
+	case PathMapKey:
+		return "map-key"
+	case PathPtrDeref:
+		return "pointer-deref"
+	case PathIfaceUnwrap:
+		return "interface-unwrap"
+	case PathTransform:
+		return "transform"
+	}
+
+	return fmt.Sprintf("PathElemKind(%d)", int(pek))
+}
+
+// PathElem represents a single step in the Path from the top-level values
+// passed to DiffVals to the point at which a difference was found. The
+// Kind determines which of the other fields is meaningful.
+type PathElem struct {
+	Kind  PathElemKind
+	Name  string
+	Index int
+	Key   reflect.Value
+}
+
+// String returns the PathElem formatted as it would be shown in a
+// DiffValErr. Map keys which are strings are shown quoted, as they are
+// given in a PathPattern. Note that pointer dereferences and interface
+// unwrapping are not shown. Transformations are shown as the transformer
+// name in braces.
+func (pe PathElem) String() string {
+	switch pe.Kind {
+	case PathField:
+		return "." + pe.Name
+	case PathIndex:
+		return fmt.Sprintf("[%d]", pe.Index)
+	case PathMapKey:
+		if pe.Key.Kind() == reflect.String {
+			return "[" + strconv.Quote(pe.Key.String()) + "]"
+		}
+
+		return fmt.Sprintf("[%v]", pe.Key)
+	case PathTransform:
+		return "{" + pe.Name + "}"
+	case PathPtrDeref, PathIfaceUnwrap:
+		return ""
+	}
+
+	return ""
+}
+
+// Path represents the chain of steps from the top-level values passed to
+// DiffVals to the point at which a difference was found.
+type Path []PathElem
+
+// String returns the Path formatted as it is shown in a DiffValErr. The
+// top-level value is shown as "this".
+func (p Path) String() string {
+	var b strings.Builder
+
+	b.WriteString("this")
+
+	for _, pe := range p {
+		b.WriteString(pe.String())
+	}
+
+	return b.String()
+}
+
+// add returns a copy of the Path with the PathElem added. The new Path
+// never shares storage with the original so that sibling paths cannot
+// overwrite one another.
+func (p Path) add(pe PathElem) Path {
+	newPath := make(Path, len(p), len(p)+1)
+	copy(newPath, p)
+
+	return append(newPath, pe)
+}
+
+// unexportedField returns the name of the first unexported struct field in
+// the Path. It returns false if there is no unexported field.
+func (p Path) unexportedField() (string, bool) {
+	for _, pe := range p {
+		if pe.Kind == PathField && !token.IsExported(pe.Name) {
+			return pe.Name, true
+		}
+	}
+
+	return "", false
+}
@@ -1,6 +1,9 @@
 package testhelper_test
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"unsafe"
 
@@ -156,7 +159,7 @@ func TestDiffVals(t *testing.T) {
 			actVal: uint(42),
 			expVal: uint(43),
 			ExpErr: testhelper.MkExpErr(`this: uint values differ.`,
-				"Actual: 42, expected: 43"),
+				"Actual: uint(42), expected: uint(43)"),
 		},
 		{
 			ID:     testhelper.MkID("same val, float"),
@@ -180,7 +183,7 @@ func TestDiffVals(t *testing.T) {
 			actVal: complex(1, 2),
 			expVal: complex(3, 4),
 			ExpErr: testhelper.MkExpErr(`this: complex values differ.`,
-				"Actual: (1+2i), expected: (3+4i)"),
+				"Actual: complex(1, 2), expected: complex(3, 4)"),
 		},
 		{
 			ID:     testhelper.MkID("same val, map"),
@@ -201,28 +204,36 @@ func TestDiffVals(t *testing.T) {
 			ID:     testhelper.MkID("value diff by len, map"),
 			actVal: map[string]any{"a": "A", "b": 42},
 			expVal: map[string]any{"a": "A"},
-			ExpErr: testhelper.MkExpErr(`this: map lengths differ.`,
-				`Actual: 2, expected: 1`),
+			ExpErr: testhelper.MkExpErr(`this: map keys differ.`,
+				`Present in actual but not expected: ["b"]`),
 		},
 		{
 			ID:     testhelper.MkID("value diff by keys, map"),
-			actVal: map[string]any{"a": "A", "b": 42},
-			expVal: map[string]any{"a": "A", "c": 42},
-			ExpErr: testhelper.MkExpErr(`this[b]:` +
-				` the expected value is invalid, the actual value is not`),
+			actVal: map[string]any{"a": "A", "b": 42, "d": 1, "e": 2},
+			expVal: map[string]any{"a": "A", "c": 42, "d": 1, "e": 2},
+			ExpErr: testhelper.MkExpErr(`this: map keys differ.` +
+				` Present in actual but not expected: ["b"],` +
+				` Missing from actual: ["c"]`),
+		},
+		{
+			ID:     testhelper.MkID("value diff by missing keys, map"),
+			actVal: map[int]any{1: "A"},
+			expVal: map[int]any{1: "A", 3: 42, 2: 42, 10: 1},
+			ExpErr: testhelper.MkExpErr(`this: map keys differ.` +
+				` Missing from actual: [2 3 10]`),
 		},
 		{
 			ID:     testhelper.MkID("value diff by value, map"),
 			actVal: map[string]any{"a": "A", "b": 42},
 			expVal: map[string]any{"a": "Not-A", "b": 42},
-			ExpErr: testhelper.MkExpErr(`this[a]: strings differ.`,
+			ExpErr: testhelper.MkExpErr(`this["a"]: strings differ.`,
 				`Actual: "A", expected: "Not-A"`),
 		},
 		{
 			ID:     testhelper.MkID("value diff by type, map"),
 			actVal: map[string]any{"a": "A", "b": 42},
 			expVal: map[string]any{"a": 3.14159, "b": 42},
-			ExpErr: testhelper.MkExpErr(`this[a]: types differ.`,
+			ExpErr: testhelper.MkExpErr(`this["a"]: types differ.`,
 				"Actual: string, expected: float64"),
 		},
 		{
@@ -309,6 +320,17 @@ func TestDiffVals(t *testing.T) {
 			ExpErr: testhelper.MkExpErr(`this: strings differ.`,
 				`Actual: "Hello", expected: "Goodbye"`),
 		},
+		{
+			ID:     testhelper.MkID("vals differ, multi-line string"),
+			actVal: "Hello\nWorld\n",
+			expVal: "Hello\nThere\n",
+			ExpErr: testhelper.MkExpErr(
+				"this: strings differ (-expected +actual):\n"+
+					"--- expected\n"+
+					"+++ actual\n"+
+					"@@ -1,2 +1,2 @@\n",
+				"-There\n+World"),
+		},
 		{
 			ID:     testhelper.MkID("same pointer, unsafePointer"),
 			actVal: unsafe.Pointer(&i),
@@ -452,3 +474,240 @@ func TestDiffVals(t *testing.T) {
 		testhelper.CheckExpErr(t, err, tc)
 	}
 }
+
+func TestDiffValsAll(t *testing.T) {
+	type threeInts struct {
+		a, b, c int
+	}
+
+	// fieldDiff returns the description of a difference between the int
+	// values of one of the (unexported) fields of a threeInts
+	fieldDiff := func(path string, act, exp int) string {
+		field := path[strings.LastIndex(path, ".")+1:]
+
+		return fmt.Sprintf("%s: int values differ. Actual: %d, expected: %d"+
+			" (in the unexported field: %s;"+
+			" see DiffValsCfg.IgnoreUnexported)",
+			path, act, exp, field)
+	}
+
+	testCases := []struct {
+		testhelper.ID
+		actVal   any
+		expVal   any
+		ignore   [][]string
+		expCount int
+		testhelper.ExpErr
+	}{
+		{
+			ID:     testhelper.MkID("same vals"),
+			actVal: threeInts{a: 1, b: 2, c: 3},
+			expVal: threeInts{a: 1, b: 2, c: 3},
+		},
+		{
+			ID:       testhelper.MkID("exp nil, act not"),
+			actVal:   42,
+			expCount: 1,
+			ExpErr: testhelper.MkExpErr("the expected value is nil," +
+				" the actual value is not"),
+		},
+		{
+			ID:       testhelper.MkID("one difference"),
+			actVal:   threeInts{a: 1, b: 2, c: 3},
+			expVal:   threeInts{a: 1, b: 2, c: 4},
+			expCount: 1,
+			ExpErr: testhelper.MkExpErr(`this.c: int values differ.`,
+				"Actual: 3, expected: 4"),
+		},
+		{
+			ID:       testhelper.MkID("all fields differ"),
+			actVal:   threeInts{a: 1, b: 2, c: 3},
+			expVal:   threeInts{a: 4, b: 5, c: 6},
+			expCount: 3,
+			ExpErr: testhelper.MkExpErr(
+				fieldDiff("this.a", 1, 4) + "\n" +
+					fieldDiff("this.b", 2, 5) + "\n" +
+					fieldDiff("this.c", 3, 6)),
+		},
+		{
+			ID:       testhelper.MkID("all fields differ, one ignored"),
+			actVal:   threeInts{a: 1, b: 2, c: 3},
+			expVal:   threeInts{a: 4, b: 5, c: 6},
+			ignore:   [][]string{{"b"}},
+			expCount: 2,
+			ExpErr: testhelper.MkExpErr(
+				fieldDiff("this.a", 1, 4) + "\n" +
+					fieldDiff("this.c", 3, 6)),
+		},
+		{
+			ID:       testhelper.MkID("nested differences"),
+			actVal:   []threeInts{{a: 1, b: 2, c: 3}, {a: 1, b: 2, c: 3}},
+			expVal:   []threeInts{{a: 1, b: 0, c: 3}, {a: 1, b: 2, c: 0}},
+			expCount: 2,
+			ExpErr: testhelper.MkExpErr(
+				fieldDiff("this[0].b", 2, 0) + "\n" +
+					fieldDiff("this[1].c", 3, 0)),
+		},
+		{
+			ID:       testhelper.MkID("map keys and values differ"),
+			actVal:   map[string]int{"z": 1, "b": 2, "y": 3, "a": 4, "c": 5},
+			expVal:   map[string]int{"z": 0, "b": 2, "y": 0, "d": 4, "c": 5},
+			expCount: 3,
+			ExpErr: testhelper.MkExpErr(
+				"this: map keys differ." +
+					" Present in actual but not expected: [\"a\"]," +
+					" Missing from actual: [\"d\"]\n" +
+					"this[\"y\"]: int values differ. Actual: 3, expected: 0\n" +
+					"this[\"z\"]: int values differ. Actual: 1, expected: 0"),
+		},
+		{
+			ID: testhelper.MkID("too many differences"),
+			actVal: []threeInts{
+				{a: 1, b: 2, c: 3},
+				{a: 1, b: 2, c: 3},
+				{a: 1, b: 2, c: 3},
+			},
+			expVal: []threeInts{
+				{a: 0, b: 0, c: 0},
+				{a: 0, b: 0, c: 0},
+				{a: 0, b: 0, c: 0},
+			},
+			expCount: 9,
+			ExpErr: testhelper.MkExpErr(
+				fieldDiff("this[1].b", 2, 0) + "\n" +
+					"... 9 differences found, 4 not shown"),
+		},
+	}
+
+	for _, tc := range testCases {
+		err := testhelper.DiffValsAll(tc.actVal, tc.expVal, tc.ignore...)
+		if !testhelper.CheckExpErr(t, err, tc) || err == nil {
+			continue
+		}
+
+		var dves testhelper.DiffValErrs
+		if !errors.As(err, &dves) {
+			t.Log(tc.IDStr())
+			t.Errorf("\t: the error should be a DiffValErrs, it is a %T", err)
+
+			continue
+		}
+
+		testhelper.DiffInt(t, tc.IDStr(), "count", dves.Count, tc.expCount)
+
+		var dve testhelper.DiffValErr
+		if !errors.As(err, &dve) {
+			t.Log(tc.IDStr())
+			t.Errorf("\t: the error should contain a DiffValErr")
+		}
+	}
+}
+
+func TestDiffValErrPath(t *testing.T) {
+	type inner struct {
+		Vals map[string][]int
+	}
+
+	type outer struct {
+		In any
+		P  *inner
+	}
+
+	testCases := []struct {
+		testhelper.ID
+		actVal  any
+		expVal  any
+		expPath testhelper.Path
+		expStr  string
+		expAct  any
+		expExp  any
+	}{
+		{
+			ID: testhelper.MkID("nested in map and slice"),
+			actVal: outer{
+				P: &inner{Vals: map[string][]int{"a": {1, 2}}},
+			},
+			expVal: outer{
+				P: &inner{Vals: map[string][]int{"a": {1, 3}}},
+			},
+			expPath: testhelper.Path{
+				{Kind: testhelper.PathField, Name: "P"},
+				{Kind: testhelper.PathPtrDeref},
+				{Kind: testhelper.PathField, Name: "Vals"},
+				{Kind: testhelper.PathMapKey},
+				{Kind: testhelper.PathIndex, Index: 1},
+			},
+			expStr: "this.P.Vals[\"a\"][1]",
+			expAct: 2,
+			expExp: 3,
+		},
+		{
+			ID:     testhelper.MkID("in interface"),
+			actVal: outer{In: "hello"},
+			expVal: outer{In: "world"},
+			expPath: testhelper.Path{
+				{Kind: testhelper.PathField, Name: "In"},
+				{Kind: testhelper.PathIfaceUnwrap},
+			},
+			expStr: "this.In",
+			expAct: "hello",
+			expExp: "world",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := testhelper.DiffVals(tc.actVal, tc.expVal)
+
+		var dve testhelper.DiffValErr
+		if !errors.As(err, &dve) {
+			t.Log(tc.IDStr())
+			t.Errorf("\t: expected a DiffValErr, got: %v", err)
+
+			continue
+		}
+
+		testhelper.DiffString(t, tc.IDStr(), "path", dve.Path.String(),
+			tc.expStr)
+
+		// the path, without the leading "this", should match itself when
+		// used as a pattern
+		pattern := strings.TrimPrefix(
+			strings.TrimPrefix(dve.Path.String(), "this"), ".")
+
+		pp, err := testhelper.ParsePathPattern(pattern)
+		if err != nil {
+			t.Log(tc.IDStr())
+			t.Errorf("\t: the path should parse as a PathPattern: %v", err)
+		} else if !pp.Matches(dve.Path) {
+			t.Log(tc.IDStr())
+			t.Errorf("\t: the path should match itself as a PathPattern")
+		}
+
+		if testhelper.DiffInt(t, tc.IDStr(), "path length",
+			len(dve.Path), len(tc.expPath)) {
+			continue
+		}
+
+		for i, pe := range dve.Path {
+			name := fmt.Sprintf("path[%d]", i)
+			testhelper.DiffString(t, tc.IDStr(), name+" kind",
+				pe.Kind.String(), tc.expPath[i].Kind.String())
+			testhelper.DiffString(t, tc.IDStr(), name+" name",
+				pe.Name, tc.expPath[i].Name)
+			testhelper.DiffInt(t, tc.IDStr(), name+" index",
+				pe.Index, tc.expPath[i].Index)
+		}
+
+		err = testhelper.DiffVals(dve.Act.Interface(), tc.expAct)
+		if err != nil {
+			t.Log(tc.IDStr())
+			t.Errorf("\t: bad actual value: %v", err)
+		}
+
+		err = testhelper.DiffVals(dve.Exp.Interface(), tc.expExp)
+		if err != nil {
+			t.Log(tc.IDStr())
+			t.Errorf("\t: bad expected value: %v", err)
+		}
+	}
+}
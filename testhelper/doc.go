@@ -3,7 +3,7 @@ Package testhelper contains helper functions for doing things you might want
 to do in lots of your tests. For instance checking that an error value or a
 panic status was as expected.
 
-The functions where a 't *testing.T' is passed will mark themselves as
+The functions where a 't testing.TB' is passed will mark themselves as
 helpers by calling t.Helper(). These will also report the error themselves.
 
 The testID parameter that many of the helpers take should be a string which
@@ -1,6 +1,7 @@
 package testhelper
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -49,14 +50,15 @@ type TestCaseWithErr interface {
 
 // CheckExpErr calls CheckError using the details from the test case to supply
 // the parameters
-func CheckExpErr(t *testing.T, err error, tce TestCaseWithErr) bool {
+func CheckExpErr(t testing.TB, err error, tce TestCaseWithErr) bool {
 	t.Helper()
-	return CheckError(t, tce.IDStr(), err, tce.ErrExpected(), tce.ErrShldCont())
+	return checkError(t, tce.IDStr(), tcLocation(tce),
+		err, tce.ErrExpected(), tce.ErrShldCont())
 }
 
 // CheckExpErrWithID calls CheckError using the details from the TestErr to
 // supply the parameters. The testID is supplied separately
-func CheckExpErrWithID(t *testing.T, testID string, err error, te TestErr) bool {
+func CheckExpErrWithID(t testing.TB, testID string, err error, te TestErr) bool {
 	t.Helper()
 	return CheckError(t, testID, err, te.ErrExpected(), te.ErrShldCont())
 }
@@ -65,25 +67,39 @@ func CheckExpErrWithID(t *testing.T, testID string, err error, te TestErr) bool
 // non-nil if it is expected and that it contains the expected content if it
 // is expected and non-nil. It will return false if there is any problem with
 // the error, true otherwise
-func CheckError(t *testing.T, testID string, err error, expected bool, shouldContain []string) bool {
+func CheckError(t testing.TB, testID string, err error, expected bool, shouldContain []string) bool {
+	t.Helper()
+	return checkError(t, testID, "", err, expected, shouldContain)
+}
+
+// checkError checks the error as described for CheckError. The location
+// (at) of the test case is given if it is known.
+func checkError(t testing.TB, testID, at string,
+	err error, expected bool, shouldContain []string,
+) bool {
 	t.Helper()
 
 	if err != nil {
 		if !expected {
-			t.Log(testID)
-			t.Log("\t: unexpected error:")
-			t.Logf("\t\t%s", err)
-			t.Errorf("\t: no error was expected")
+			f := newFailure(testID, "CheckError", "error")
+			f.At = at
+			f.Act = failureVal(err)
+			f.log("\t: unexpected error:")
+			f.logf("\t\t%s", err)
+			f.report(t, "\t: no error was expected")
 
 			return false
 		}
 
-		return !ShouldContain(t, testID, "error", err.Error(), shouldContain)
+		return !shouldContainAt(t, testID, at,
+			"error", err.Error(), shouldContain)
 	}
 
 	if expected {
-		t.Log(testID)
-		t.Error("\t: an error was expected but none was returned")
+		f := newFailure(testID, "CheckError", "error")
+		f.At = at
+		f.Exp = strings.Join(shouldContain, ", ")
+		f.report(t, "\t: an error was expected but none was returned")
 
 		return false
 	}
@@ -0,0 +1,167 @@
+package testhelper
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+// FakeTB is a testing.TB which records the messages logged and whether an
+// error has been reported rather than passing them to a real test. This
+// allows the failure paths of the helpers to be tested. It is only declared
+// in the tests but is exported so that the external tests can use it too.
+type FakeTB struct {
+	testing.TB
+
+	Logs   []string
+	failed bool
+}
+
+func (ft *FakeTB) Helper() {}
+
+func (ft *FakeTB) Log(args ...any) {
+	ft.Logs = append(ft.Logs, fmt.Sprintln(args...))
+}
+
+func (ft *FakeTB) Logf(format string, args ...any) {
+	ft.Logs = append(ft.Logs, fmt.Sprintf(format, args...))
+}
+
+func (ft *FakeTB) Error(args ...any) {
+	ft.Log(args...)
+	ft.failed = true
+}
+
+func (ft *FakeTB) Errorf(format string, args ...any) {
+	ft.Logf(format, args...)
+	ft.failed = true
+}
+
+func (ft *FakeTB) Failed() bool { return ft.failed }
+
+// ErrFakeFailNow is the value with which the FakeTB panics when FailNow is
+// called. This stops the check in the same way as runtime.Goexit would
+// stop the test but allows the test to continue.
+var ErrFakeFailNow = errors.New("FailNow called")
+
+func (ft *FakeTB) FailNow() {
+	ft.failed = true
+	panic(ErrFakeFailNow)
+}
+
+// Logged returns all the logged messages as a single string
+func (ft *FakeTB) Logged() string {
+	return strings.Join(ft.Logs, "")
+}
+
+// CheckResult checks the outcome of calling a check func with the
+// FakeTB. A problem should have been reported (the check func should have
+// returned true and failed the FakeTB) if and only if a log is expected and
+// the messages logged should be exactly as expected.
+func (ft *FakeTB) CheckResult(t *testing.T, id string,
+	reported bool, expLog string,
+) {
+	t.Helper()
+
+	expReported := expLog != ""
+	DiffBool(t, id, "problem reported", reported, expReported)
+	DiffBool(t, id, "failed", ft.Failed(), expReported)
+	DiffString(t, id, "log", ft.Logged(), expLog)
+}
+
+func TestHelperFailures(t *testing.T) {
+	testCases := []struct {
+		ID
+		f      func(t testing.TB) bool
+		expLog []string
+	}{
+		{
+			ID: MkID("DiffInt"),
+			f: func(t testing.TB) bool {
+				return DiffInt(t, "id", "val", 1, 2)
+			},
+			expLog: []string{
+				"id\n",
+				"expected val:     2",
+				"  actual val:     1",
+				"val is incorrect",
+			},
+		},
+		{
+			ID: MkID("DiffString"),
+			f: func(t testing.TB) bool {
+				return DiffString(t, "id", "str", "abc", "abd")
+			},
+			expLog: []string{"str is incorrect"},
+		},
+		{
+			ID: MkID("DiffErrIs"),
+			f: func(t testing.TB) bool {
+				return DiffErrIs(t, "id", "err",
+					errors.New("a"), errors.ErrUnsupported)
+			},
+			expLog: []string{
+				"expected err to match (using errors.Is):" +
+					` *errors.errorString: "unsupported operation"`,
+				`*errors.errorString: "a"`,
+			},
+		},
+		{
+			ID: MkID("DiffErrTree"),
+			f: func(t testing.TB) bool {
+				return DiffErrTree(t, "id", "err",
+					fmt.Errorf("b: %w", errors.New("a")),
+					fmt.Errorf("b: %w", errors.New("c")))
+			},
+			expLog: []string{
+				"err error trees differ",
+				`*   *errors.errorString: "c"`,
+			},
+		},
+		{
+			ID: MkID("DiffSliceUnordered, NaN missing"),
+			f: func(t testing.TB) bool {
+				return DiffSliceUnordered(t, "id", "vals",
+					[]float64{}, []float64{math.NaN()})
+			},
+			expLog: []string{
+				"   missing vals [0]: math.NaN()",
+				"vals is incorrect",
+			},
+		},
+		{
+			ID: MkID("ShouldContain"),
+			f: func(t testing.TB) bool {
+				return ShouldContain(t, "id", "str",
+					"abc", []string{"xyz"})
+			},
+			expLog: []string{"xyz"},
+		},
+		{
+			ID: MkID("CheckError"),
+			f: func(t testing.TB) bool {
+				return !CheckError(t, "id",
+					errors.New("oops"), false, nil)
+			},
+			expLog: []string{"oops"},
+		},
+	}
+
+	for _, tc := range testCases {
+		ft := &FakeTB{TB: t}
+
+		if !tc.f(ft) {
+			t.Log(tc.IDStr())
+			t.Error("\t: the helper should have reported a problem")
+		}
+
+		if !ft.Failed() {
+			t.Log(tc.IDStr())
+			t.Error("\t: the fake testing.TB should have been failed")
+		}
+
+		ShouldContain(t, tc.IDStr(), "log", ft.Logged(), tc.expLog)
+	}
+}
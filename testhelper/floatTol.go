@@ -0,0 +1,134 @@
+package testhelper
+
+import (
+	"fmt"
+	"math"
+)
+
+// FloatTol holds the tolerances to be used when comparing floating point
+// values. Two values are taken to be equal if they differ by less than the
+// absolute tolerance (Abs) or if their difference is less than the
+// relative tolerance (Rel) multiplied by the larger of their absolute
+// values or if they are no more than ULPs units in the last place apart
+// (that is, there are fewer than ULPs representable values between
+// them). The comparisons with Abs and Rel are strict, as for the epsilon
+// passed to DiffFloat, so the zero value requires the values to be
+// exactly equal.
+//
+// Regardless of the tolerances, an infinity is only equal to an infinity
+// of the same sign. A NaN is not equal to any value, not even another NaN,
+// as for the == operator, unless NaNsEqual is set in which case two NaN
+// values are taken to be equal. Set NaNsEqual to check that a value is
+// expected to be NaN.
+type FloatTol struct {
+	Abs       float64
+	Rel       float64
+	ULPs      uint64
+	NaNsEqual bool
+}
+
+// IsZero returns true if no tolerance is allowed
+func (ft FloatTol) IsZero() bool {
+	return ft.Abs == 0 && ft.Rel == 0 && ft.ULPs == 0 && !ft.NaNsEqual
+}
+
+// String returns a string describing the tolerances
+func (ft FloatTol) String() string {
+	s := fmt.Sprintf("abs: %g, rel: %g", ft.Abs, ft.Rel)
+
+	if ft.ULPs != 0 {
+		s += fmt.Sprintf(", ulps: %d", ft.ULPs)
+	}
+
+	if ft.NaNsEqual {
+		s += ", NaNs equal"
+	}
+
+	return s
+}
+
+// Equal returns true if the two values are equal within the tolerances. The
+// distance in units in the last place is measured between the float64
+// values.
+func (ft FloatTol) Equal(a, b float64) bool {
+	return ft.equalBits(a, b, 64) //nolint:mnd
+}
+
+// equalBits returns true if the two values are equal within the
+// tolerances. The bits gives the size of the values being compared (32 or
+// 64) and is used to measure the distance in units in the last place.
+func (ft FloatTol) equalBits(a, b float64, bits int) bool {
+	aIsNaN := math.IsNaN(a)
+	bIsNaN := math.IsNaN(b)
+
+	if aIsNaN || bIsNaN {
+		return ft.NaNsEqual && aIsNaN && bIsNaN
+	}
+
+	if a == b {
+		return true
+	}
+
+	if math.IsInf(a, 0) || math.IsInf(b, 0) {
+		return false
+	}
+
+	diff := math.Abs(a - b)
+	if diff < ft.Abs {
+		return true
+	}
+
+	if diff < ft.Rel*math.Max(math.Abs(a), math.Abs(b)) {
+		return true
+	}
+
+	return ft.ULPs != 0 && ulpDistance(a, b, bits) <= ft.ULPs
+}
+
+// ulpDistance returns the number of units in the last place between the two
+// values, that is, the number of representable values between them plus
+// one. The bits gives the size of the values (32 or 64). The values should
+// not be NaN.
+func ulpDistance(a, b float64, bits int) uint64 {
+	var ia, ib int64
+
+	if bits == 32 { //nolint:mnd
+		ia = int64(orderedBits32(float32(a)))
+		ib = int64(orderedBits32(float32(b)))
+	} else {
+		ia = orderedBits64(a)
+		ib = orderedBits64(b)
+	}
+
+	// the subtraction is done with unsigned values as the difference might
+	// overflow an int64
+	if ia > ib {
+		return uint64(ia) - uint64(ib) //nolint:gosec
+	}
+
+	return uint64(ib) - uint64(ia) //nolint:gosec
+}
+
+// orderedBits64 returns the bits of the float64 as an integer such that the
+// integers are in the same order as the float values. Positive and
+// negative zero both give zero.
+func orderedBits64(f float64) int64 {
+	i := int64(math.Float64bits(f)) //nolint:gosec
+	if i < 0 {
+		return math.MinInt64 - i
+	}
+
+	return i
+}
+
+// orderedBits32 returns the bits of the float32 as an integer such that the
+// integers are in the same order as the float values. Positive and
+// negative zero both give zero.
+func orderedBits32(f float32) int32 {
+	i := int32(math.Float32bits(f)) //nolint:gosec
+	if i < 0 {
+		return math.MinInt32 - i
+	}
+
+	return i
+}
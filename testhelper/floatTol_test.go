@@ -0,0 +1,359 @@
+package testhelper_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestFloatTolEqual(t *testing.T) {
+	nan := math.NaN()
+	inf := math.Inf(1)
+
+	testCases := []struct {
+		testhelper.ID
+		ft       testhelper.FloatTol
+		a, b     float64
+		expEqual bool
+	}{
+		{
+			ID:       testhelper.MkID("no tolerance, equal"),
+			a:        1.5,
+			b:        1.5,
+			expEqual: true,
+		},
+		{
+			ID: testhelper.MkID("no tolerance, differ"),
+			a:  1.5,
+			b:  1.5000001,
+		},
+		{
+			ID:       testhelper.MkID("abs tolerance, within"),
+			ft:       testhelper.FloatTol{Abs: 0.1},
+			a:        1.5,
+			b:        1.55,
+			expEqual: true,
+		},
+		{
+			ID: testhelper.MkID("abs tolerance, outside"),
+			ft: testhelper.FloatTol{Abs: 0.1},
+			a:  1.5,
+			b:  1.65,
+		},
+		{
+			ID: testhelper.MkID("abs tolerance, on the boundary"),
+			ft: testhelper.FloatTol{Abs: 0.25},
+			a:  1.5,
+			b:  1.75,
+		},
+		{
+			ID:       testhelper.MkID("rel tolerance, within"),
+			ft:       testhelper.FloatTol{Rel: 1e-6},
+			a:        1e12,
+			b:        1e12 + 1e5,
+			expEqual: true,
+		},
+		{
+			ID: testhelper.MkID("rel tolerance, outside"),
+			ft: testhelper.FloatTol{Rel: 1e-6},
+			a:  1e-12,
+			b:  2e-12,
+		},
+		{
+			ID: testhelper.MkID("rel tolerance, on the boundary"),
+			ft: testhelper.FloatTol{Rel: 0.5},
+			a:  1,
+			b:  2,
+		},
+		{
+			ID: testhelper.MkID("both NaN"),
+			ft: testhelper.FloatTol{Abs: math.MaxFloat64},
+			a:  nan,
+			b:  nan,
+		},
+		{
+			ID:       testhelper.MkID("both NaN, NaNs equal"),
+			ft:       testhelper.FloatTol{NaNsEqual: true},
+			a:        nan,
+			b:        nan,
+			expEqual: true,
+		},
+		{
+			ID: testhelper.MkID("one NaN, NaNs equal"),
+			ft: testhelper.FloatTol{NaNsEqual: true},
+			a:  nan,
+			b:  1,
+		},
+		{
+			ID: testhelper.MkID("one NaN"),
+			ft: testhelper.FloatTol{Abs: math.MaxFloat64},
+			a:  nan,
+			b:  1,
+		},
+		{
+			ID:       testhelper.MkID("both +Inf"),
+			a:        inf,
+			b:        inf,
+			expEqual: true,
+		},
+		{
+			ID: testhelper.MkID("+Inf and -Inf"),
+			ft: testhelper.FloatTol{Abs: math.MaxFloat64},
+			a:  inf,
+			b:  -inf,
+		},
+		{
+			ID: testhelper.MkID("+Inf and a large value"),
+			ft: testhelper.FloatTol{Rel: 1},
+			a:  inf,
+			b:  math.MaxFloat64,
+		},
+		{
+			ID:       testhelper.MkID("ulps tolerance, within"),
+			ft:       testhelper.FloatTol{ULPs: 2},
+			a:        1,
+			b:        math.Nextafter(math.Nextafter(1, 2), 2),
+			expEqual: true,
+		},
+		{
+			ID: testhelper.MkID("ulps tolerance, outside"),
+			ft: testhelper.FloatTol{ULPs: 1},
+			a:  1,
+			b:  math.Nextafter(math.Nextafter(1, 2), 2),
+		},
+		{
+			ID:       testhelper.MkID("ulps tolerance, across zero"),
+			ft:       testhelper.FloatTol{ULPs: 2},
+			a:        -math.SmallestNonzeroFloat64,
+			b:        math.SmallestNonzeroFloat64,
+			expEqual: true,
+		},
+		{
+			ID:       testhelper.MkID("ulps tolerance, extremes"),
+			ft:       testhelper.FloatTol{ULPs: math.MaxUint64},
+			a:        -math.MaxFloat64,
+			b:        math.MaxFloat64,
+			expEqual: true,
+		},
+		{
+			ID: testhelper.MkID("ulps tolerance, +Inf and a large value"),
+			ft: testhelper.FloatTol{ULPs: 1},
+			a:  inf,
+			b:  math.MaxFloat64,
+		},
+	}
+
+	for _, tc := range testCases {
+		testhelper.DiffBool(t, tc.IDStr(), "equal",
+			tc.ft.Equal(tc.a, tc.b), tc.expEqual)
+	}
+}
+
+func TestFloatTolString(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		ft     testhelper.FloatTol
+		expStr string
+	}{
+		{
+			ID:     testhelper.MkID("zero"),
+			expStr: "abs: 0, rel: 0",
+		},
+		{
+			ID:     testhelper.MkID("with ulps"),
+			ft:     testhelper.FloatTol{Abs: 0.5, ULPs: 4},
+			expStr: "abs: 0.5, rel: 0, ulps: 4",
+		},
+		{
+			ID:     testhelper.MkID("NaNs equal"),
+			ft:     testhelper.FloatTol{Rel: 0.1, NaNsEqual: true},
+			expStr: "abs: 0, rel: 0.1, NaNs equal",
+		},
+	}
+
+	for _, tc := range testCases {
+		testhelper.DiffString(t, tc.IDStr(), "string", tc.ft.String(),
+			tc.expStr)
+	}
+}
+
+func TestDiffFloatTol(t *testing.T) {
+	nan := math.NaN()
+	inf := math.Inf(1)
+
+	var f32 float32 = 1
+
+	next32 := math.Nextafter32(f32, 2)
+
+	testCases := []struct {
+		testhelper.ID
+		f      func(t testing.TB) bool
+		expLog string
+	}{
+		{
+			ID: testhelper.MkID("float32, 1 ulp"),
+			f: func(t testing.TB) bool {
+				return testhelper.DiffFloatTol(t, "id", "value",
+					next32, f32, testhelper.FloatTol{ULPs: 1})
+			},
+		},
+		{
+			ID: testhelper.MkID("NaNs equal"),
+			f: func(t testing.TB) bool {
+				return testhelper.DiffFloatTol(t, "id", "value",
+					nan, nan, testhelper.FloatTol{NaNsEqual: true})
+			},
+		},
+		{
+			ID: testhelper.MkID("NaNs differ by default"),
+			f: func(t testing.TB) bool {
+				return testhelper.DiffFloatTol(t, "id", "value",
+					nan, nan, testhelper.FloatTol{})
+			},
+			expLog: "id\n" +
+				"\t: tolerance: abs: 0, rel: 0\n" +
+				"\t: expected value:   NaN\n" +
+				"\t:   actual value:   NaN\n" +
+				"\t:           diff:   NaN\n" +
+				"\t:       rel diff:   NaN\n" +
+				"\t: value is incorrect\n",
+		},
+		{
+			ID: testhelper.MkID("slice, rel"),
+			f: func(t testing.TB) bool {
+				return testhelper.DiffFloatSliceTol(t, "id", "values",
+					[]float64{1e10, 1e-10}, []float64{1e10 + 1, 1.00001e-10},
+					testhelper.FloatTol{Rel: 1e-4})
+			},
+		},
+		{
+			ID: testhelper.MkID("DiffFloat, NaNs differ"),
+			f: func(t testing.TB) bool {
+				return testhelper.DiffFloat(t, "id", "value", nan, nan, 0)
+			},
+			expLog: "id\n" +
+				"\t: expected value:   NaN\n" +
+				"\t:   actual value:   NaN\n" +
+				"\t:           diff:   NaN\n" +
+				"\t:       rel diff:   NaN\n" +
+				"\t: value is incorrect\n",
+		},
+		{
+			ID: testhelper.MkID("outside abs tolerance"),
+			f: func(t testing.TB) bool {
+				return testhelper.DiffFloatTol(t, "id", "value",
+					1.5, 1.75, testhelper.FloatTol{Abs: 0.1})
+			},
+			expLog: "id\n" +
+				"\t: tolerance: abs: 0.1, rel: 0\n" +
+				"\t: expected value:  1.75\n" +
+				"\t:   actual value:   1.5\n" +
+				"\t:           diff:  0.25\n" +
+				"\t:       rel diff: 0.14285714285714285\n" +
+				"\t: value is incorrect\n",
+		},
+		{
+			ID: testhelper.MkID("DiffFloat, on the epsilon boundary"),
+			f: func(t testing.TB) bool {
+				return testhelper.DiffFloat(t, "id", "value", 1.5, 1.75, 0.25)
+			},
+			expLog: "id\n" +
+				"\t: expected value:  1.75\n" +
+				"\t:   actual value:   1.5\n" +
+				"\t:           diff:  0.25\n" +
+				"\t:       rel diff: 0.14285714285714285\n" +
+				"\t: value is incorrect\n",
+		},
+		{
+			ID: testhelper.MkID("on the abs tolerance boundary"),
+			f: func(t testing.TB) bool {
+				return testhelper.DiffFloatTol(t, "id", "value",
+					1.5, 1.75, testhelper.FloatTol{Abs: 0.25})
+			},
+			expLog: "id\n" +
+				"\t: tolerance: abs: 0.25, rel: 0\n" +
+				"\t: expected value:  1.75\n" +
+				"\t:   actual value:   1.5\n" +
+				"\t:           diff:  0.25\n" +
+				"\t:       rel diff: 0.14285714285714285\n" +
+				"\t: value is incorrect\n",
+		},
+		{
+			ID: testhelper.MkID("outside rel tolerance"),
+			f: func(t testing.TB) bool {
+				return testhelper.DiffFloatTol(t, "id", "value",
+					2e-12, 1e-12, testhelper.FloatTol{Rel: 1e-6})
+			},
+			expLog: "id\n" +
+				"\t: tolerance: abs: 0, rel: 1e-06\n" +
+				"\t: expected value: 1e-12\n" +
+				"\t:   actual value: 2e-12\n" +
+				"\t:           diff: 1e-12\n" +
+				"\t:       rel diff:   0.5\n" +
+				"\t: value is incorrect\n",
+		},
+		{
+			ID: testhelper.MkID("NaN and a number"),
+			f: func(t testing.TB) bool {
+				return testhelper.DiffFloatTol(t, "id", "value",
+					nan, 1, testhelper.FloatTol{Abs: 0.1})
+			},
+			expLog: "id\n" +
+				"\t: tolerance: abs: 0.1, rel: 0\n" +
+				"\t: expected value:     1\n" +
+				"\t:   actual value:   NaN\n" +
+				"\t:           diff:   NaN\n" +
+				"\t:       rel diff:   NaN\n" +
+				"\t: value is incorrect\n",
+		},
+		{
+			ID: testhelper.MkID("number and NaN"),
+			f: func(t testing.TB) bool {
+				return testhelper.DiffFloatTol(t, "id", "value",
+					1, nan, testhelper.FloatTol{})
+			},
+			expLog: "id\n" +
+				"\t: tolerance: abs: 0, rel: 0\n" +
+				"\t: expected value:   NaN\n" +
+				"\t:   actual value:     1\n" +
+				"\t:           diff:   NaN\n" +
+				"\t:       rel diff:   NaN\n" +
+				"\t: value is incorrect\n",
+		},
+		{
+			ID: testhelper.MkID("infinities of different sign"),
+			f: func(t testing.TB) bool {
+				return testhelper.DiffFloatTol(t, "id", "value",
+					-inf, inf, testhelper.FloatTol{Rel: 0.5})
+			},
+			expLog: "id\n" +
+				"\t: tolerance: abs: 0, rel: 0.5\n" +
+				"\t: expected value:  +Inf\n" +
+				"\t:   actual value:  -Inf\n" +
+				"\t:           diff:  +Inf\n" +
+				"\t:       rel diff:   NaN\n" +
+				"\t: value is incorrect\n",
+		},
+		{
+			ID: testhelper.MkID("infinity and a number"),
+			f: func(t testing.TB) bool {
+				return testhelper.DiffFloatTol(t, "id", "value",
+					math.MaxFloat64, inf,
+					testhelper.FloatTol{Rel: 0.5, ULPs: 1})
+			},
+			expLog: "id\n" +
+				"\t: tolerance: abs: 0, rel: 0.5, ulps: 1\n" +
+				"\t: expected value:  +Inf\n" +
+				"\t:   actual value: 1.7976931348623157e+308\n" +
+				"\t:           diff:  +Inf\n" +
+				"\t:       rel diff:   NaN\n" +
+				"\t: value is incorrect\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		ft := &fakeTB{TB: t}
+		ft.CheckResult(t, tc.IDStr(), tc.f(ft), tc.expLog)
+	}
+}
@@ -146,7 +146,7 @@ type GoldenFileCfg struct {
 // testhelper.CheckAgainstGoldenFile function) is that this will show the
 // name of the flag to use in order to update the files. You save the hassle
 // of scanning the code to find out what you called the flag.
-func (gfc GoldenFileCfg) Check(t *testing.T, id, gfName string, val []byte) bool {
+func (gfc GoldenFileCfg) Check(t testing.TB, id, gfName string, val []byte) bool {
 	t.Helper()
 
 	if gfc.UpdFlagName != "" && !gfc.updFlagAdded {
@@ -174,7 +174,7 @@ func (gfc GoldenFileCfg) Check(t *testing.T, id, gfName string, val []byte) bool
 // names and any prefix or suffix to the supplied string to give a well-formed
 // name using the appropriate filepath separators for the operating system. A
 // suggested name to pass to this method might be the name of the current
-// test as given by the Name() method on testing.T.
+// test as given by the Name() method on testing.TB.
 //
 // Note that any supplied name is "cleaned" by removing any part prior to an
 // embedded filepath.Separator.
@@ -228,7 +228,7 @@ func (gfc GoldenFileCfg) PathName(name string) string {
 // Give the -v argument to go test to see what is being updated.
 //
 // Deprecated: use the Check method on the GoldenFileCfg
-func CheckAgainstGoldenFile(t *testing.T, testID string,
+func CheckAgainstGoldenFile(t testing.TB, testID string,
 	val []byte, gfName string, updGF bool,
 ) bool {
 	t.Helper()
@@ -236,48 +236,77 @@ func CheckAgainstGoldenFile(t *testing.T, testID string,
 	return checkFile(t, testID, gfName, val, updGF)
 }
 
-// getExpVal reads the contents of the golden file. If the updGF flag is set
-// then if will write the contents of the file before reading it. It returns
-// the contents and true if all went well, nil and false otherwise. It will
-// report any errors it finds including any problems reading from or writing
-// to the golden file itself.
-func getExpVal(t *testing.T, id, gfName string, val []byte, updGF bool,
+// newGoldenFailure returns a Failure for a problem found when checking the
+// value against the golden file
+func newGoldenFailure(id, gfName string, val []byte) *Failure {
+	f := newFailure(id, "GoldenFile", "")
+	f.File = gfName
+	f.Act = string(val)
+
+	return f
+}
+
+// getExpVal reads the contents of the golden file named in the Failure. If
+// the updGF flag is set then if will write the contents of the file before
+// reading it. It returns the contents and true if all went well, nil and
+// false otherwise. It will report any errors it finds including any
+// problems reading from or writing to the golden file itself.
+func getExpVal(t testing.TB, f *Failure, val []byte, updGF bool,
 ) ([]byte, bool) {
 	t.Helper()
 
 	if updGF {
-		if !updateGoldenFile(t, gfName, val) {
+		if !updateGoldenFile(t, f, val) {
 			return nil, false
 		}
 	}
 
-	expVal, err := os.ReadFile(gfName) //nolint:gosec
+	expVal, err := os.ReadFile(f.File) //nolint:gosec
 	if err != nil {
-		t.Log(id)
-		t.Logf("\t: Problem with the golden file: %q", gfName)
-		t.Errorf("\t: Couldn't read the expected value. Error: %s", err)
+		f.logf("\t: Problem with the golden file: %q", f.File)
+		f.report(t, "\t: Couldn't read the expected value. Error: %s", err)
 
 		return nil, false
 	}
 
+	f.Exp = string(expVal)
+
 	return expVal, true
 }
 
+// addActualNote adds the actual value to the notes of the Failure
+func addActualNote(f *Failure, val []byte) {
+	f.note("\t: Actual\n%s\n", val)
+}
+
 // checkFile confirms that the value given matches the contents of the golden
 // file and returns true if it does, false otherwise. It will report any
 // errors it finds including any problems reading from or writing to the
 // golden file itself. If the updGF flag is set to true then the golden file
 // will be updated with the supplied value.
-func checkFile(t *testing.T, id, gfName string, val []byte, updGF bool) bool {
+func checkFile(t testing.TB, id, gfName string, val []byte, updGF bool) bool {
 	t.Helper()
 
-	expVal, ok := getExpVal(t, id, gfName, val, updGF)
+	f := newGoldenFailure(id, gfName, val)
+	addActualNote(f, val)
+
+	expVal, ok := getExpVal(t, f, val, updGF)
 	if !ok {
-		t.Error("\t: Actual\n" + string(val))
 		return false
 	}
 
-	return actEqualsExp(t, id, gfName, val, expVal)
+	f.Notes = nil
+
+	return actEqualsExp(t, f, val, expVal)
+}
+
+// addUpdNote adds a note to the Failure giving the flag to use to update
+// the golden file, if there is one
+func (gfc GoldenFileCfg) addUpdNote(f *Failure) {
+	if gfc.UpdFlagName != "" {
+		f.note("\t: To update the golden file with the new value"+
+			" pass %q to the go test command", "-"+gfc.UpdFlagName)
+	}
 }
 
 // checkFile confirms that the value given matches the contents of the golden
@@ -285,37 +314,34 @@ func checkFile(t *testing.T, id, gfName string, val []byte, updGF bool) bool {
 // errors it finds including any problems reading from or writing to the
 // golden file itself. If the updGF flag is set to true then the golden file
 // will be updated with the supplied value.
-func (gfc GoldenFileCfg) checkFile(t *testing.T, id, gfName string, val []byte,
+func (gfc GoldenFileCfg) checkFile(t testing.TB, id, gfName string, val []byte,
 ) bool {
 	t.Helper()
 
-	expVal, ok := getExpVal(t, id, gfName, val, gfc.updFlag)
-	if !ok {
-		if gfc.UpdFlagName != "" {
-			t.Errorf("\t: To update the golden file with the new value"+
-				" pass %q to the go test command", "-"+gfc.UpdFlagName)
-		}
-
-		t.Error("\t: Actual\n" + string(val))
+	f := newGoldenFailure(id, gfName, val)
+	gfc.addUpdNote(f)
+	addActualNote(f, val)
 
+	expVal, ok := getExpVal(t, f, val, gfc.updFlag)
+	if !ok {
 		return false
 	}
 
-	if actEqualsExp(t, id, gfName, val, expVal) {
-		return true
+	f.Notes = nil
+	gfc.addUpdNote(f)
+
+	if !gfc.keepBadResultsFlag && gfc.KeepBadResultsFlagName != "" {
+		f.note("\t: To keep the (bad) Actual results for later"+
+			" investigation pass %q to the go test command",
+			"-"+gfc.KeepBadResultsFlagName)
 	}
 
-	if gfc.UpdFlagName != "" {
-		t.Errorf("\t: To update the golden file with the new value"+
-			" pass %q to the go test command", "-"+gfc.UpdFlagName)
+	if actEqualsExp(t, f, val, expVal) {
+		return true
 	}
 
 	if gfc.keepBadResultsFlag {
-		keepBadResults(t, gfName, val)
-	} else if gfc.KeepBadResultsFlagName != "" {
-		t.Errorf("\t: To keep the (bad) Actual results for later"+
-			" investigation pass %q to the go test command",
-			"-"+gfc.KeepBadResultsFlagName)
+		keepBadResults(t, id, gfName, val)
 	}
 
 	return false
@@ -323,45 +349,48 @@ func (gfc GoldenFileCfg) checkFile(t *testing.T, id, gfName string, val []byte,
 
 // actEqualsExp compares the expected value against the actual and reports any
 // difference. It will return true if they are equal and false otherwise
-func actEqualsExp(t *testing.T, id, gfName string, actVal, expVal []byte) bool {
+func actEqualsExp(t testing.TB, f *Failure, actVal, expVal []byte) bool {
 	t.Helper()
 
 	if bytes.Equal(actVal, expVal) {
 		return true
 	}
 
-	t.Log(id)
-	t.Log("\t: Expected\n" + string(expVal))
-	t.Log("\t: Actual\n" + string(actVal))
-	t.Errorf("\t: The value given differs from the golden file value: %q",
-		gfName)
+	f.log("\t: Expected\n" + string(expVal))
+	f.log("\t: Actual\n" + string(actVal))
+	f.report(t, "\t: The value given differs from the golden file value: %q",
+		f.File)
 
 	return false
 }
 
-// updateGoldenFile will attempt to update the golden file with the new
-// content and return true if it succeeds or false otherwise. If there is an
-// existing golden file it will try to preverve the contents so that they can
-// be compared with the new file. It reports its progress; if the file hasn't
-// changed it does nothing.
-func updateGoldenFile(t *testing.T, gfName string, val []byte) bool {
+// updateGoldenFile will attempt to update the golden file named in the
+// Failure with the new content and return true if it succeeds or false
+// otherwise. If there is an existing golden file it will try to preverve
+// the contents so that they can be compared with the new file. It reports
+// its progress; if the file hasn't changed it does nothing. If the golden
+// file cannot be written the problem is reported through the Failure so
+// that its notes are shown.
+func updateGoldenFile(t testing.TB, f *Failure, val []byte) bool {
 	t.Helper()
 
-	origVal, err := os.ReadFile(gfName) //nolint:gosec
+	origVal, err := os.ReadFile(f.File) //nolint:gosec
 	if err == nil {
 		if bytes.Equal(val, origVal) {
 			return true
 		}
 
-		origFileName := gfName + ".orig"
-		writeFile(t, origFileName, "original contents", origVal)
+		origFileName := f.File + ".orig"
+		writeFile(t, newGoldenFailure(f.ID, origFileName, origVal),
+			"original contents", origVal)
 	} else if !os.IsNotExist(err) {
-		t.Log("Couldn't preserve the original contents")
-		t.Logf("\t: Couldn't read the golden file: %q", gfName)
-		t.Error("\t: ", err)
+		rf := newGoldenFailure(f.ID, f.File, val)
+		rf.log("Couldn't preserve the original contents")
+		rf.logf("\t: Couldn't read the golden file: %q", f.File)
+		rf.report(t, "\t:  %s", err)
 	}
 
-	if !writeFile(t, gfName, "golden", val) {
+	if !writeFile(t, f, "golden", val) {
 		return false
 	}
 
@@ -369,26 +398,30 @@ func updateGoldenFile(t *testing.T, gfName string, val []byte) bool {
 }
 
 // keepBadResults will attempt to write the bad results to a new file.
-func keepBadResults(t *testing.T, gfName string, val []byte) {
+func keepBadResults(t testing.TB, id, gfName string, val []byte) {
 	t.Helper()
 
 	fName := gfName + ".badResults"
-	writeFile(t, fName, "bad results", val)
+	writeFile(t, newGoldenFailure(id, fName, val), "bad results", val)
 }
 
-// writeFile will write the values into the file. If the parent directories
-// do not exist then it will create them and try again.
-func writeFile(t *testing.T, fName, desc string, val []byte) (rval bool) {
+// writeFile will write the values into the file named in the Failure. If
+// the parent directories do not exist then it will create them and try
+// again. Any problem is reported through the Failure.
+func writeFile(t testing.TB, f *Failure, desc string, val []byte,
+) (rval bool) {
 	t.Helper()
 
 	rval = true
 
+	fName := f.File
+
 	var err error
 
 	defer func() {
 		if err != nil {
-			t.Logf("\t: Couldn't write to the %s file", desc)
-			t.Error("\t: ", err)
+			f.logf("\t: Couldn't write to the %s file", desc)
+			f.report(t, "\t:  %s", err)
 
 			rval = false
 		}
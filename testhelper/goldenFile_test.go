@@ -2,7 +2,9 @@ package testhelper_test
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/nickwells/testhelper.mod/v2/testhelper"
@@ -83,3 +85,40 @@ func TestGoldenFilePathName(t *testing.T) {
 		}
 	}
 }
+
+func TestGoldenFileUpdateFailure(t *testing.T) {
+	dir := t.TempDir()
+	gfName := filepath.Join(dir, "gf.txt")
+
+	// the golden file is a dangling link to a file in a missing directory
+	// so it cannot be read or written
+	err := os.Symlink(filepath.Join(dir, "missing", "gf.txt"), gfName)
+	if err != nil {
+		t.Fatal("couldn't create the symbolic link: ", err)
+	}
+
+	ft := &fakeTB{TB: t}
+	rr := &recReporter{}
+	testhelper.SetTestReporter(ft, rr)
+
+	if testhelper.CheckAgainstGoldenFile(ft, "id", []byte("val"),
+		gfName, true) {
+		t.Error("\t: the golden file check should have failed")
+	}
+
+	if len(rr.failures) != 1 {
+		t.Fatalf("\t: the update failure should be reported once,"+
+			" it was reported %d times", len(rr.failures))
+	}
+
+	f := rr.failures[0]
+	testhelper.DiffString(t, "test: update failure", "ID", f.ID, "id")
+	testhelper.DiffString(t, "test: update failure", "File", f.File, gfName)
+	testhelper.DiffString(t, "test: update failure", "Act", f.Act, "val")
+	testhelper.ShouldContain(t, "test: update failure", "details",
+		strings.Join(f.Details, ""),
+		[]string{"Couldn't write to the golden file"})
+	testhelper.ShouldContain(t, "test: update failure", "notes",
+		strings.Join(f.Notes, ""),
+		[]string{"\t: Actual\nval\n"})
+}
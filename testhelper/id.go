@@ -52,6 +52,23 @@ func (id ID) IDStrFullName() string {
 	return "test: " + id.AtFullName + ": " + id.Name
 }
 
+// atFullName returns the AtFullName field. It allows the location of a test
+// case with an ID embedded to be found (see the tcLocation func).
+func (id ID) atFullName() string {
+	return id.AtFullName
+}
+
+// tcLocation returns the location where the test case was made, as given by
+// the AtFullName of its ID, if it has one. It returns the empty string
+// otherwise.
+func tcLocation(tc TestCase) string {
+	if loc, ok := tc.(interface{ atFullName() string }); ok {
+		return loc.atFullName()
+	}
+
+	return ""
+}
+
 // TestCase is an interface wrapping the IDStr methods
 type TestCase interface {
 	IDStr() string
@@ -0,0 +1,160 @@
+package testhelper
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"testing"
+)
+
+// JSONFailuresEnvVar is the name of an environment variable giving the name
+// of a file to which a JSON record of each failure should be written. If it
+// is set when the tests start then the default Reporter is a JSONReporter
+// appending to that file (see NewJSONFileReporter). Note that the tests for
+// each package are run in the directory of that package so a relative file
+// name will give a different file for each package; use an absolute name
+// to collect all the failures in one file.
+const JSONFailuresEnvVar = "TESTHELPER_JSON_FAILURES"
+
+// FailureRecord is the record written, as a single line of JSON, for each
+// Failure reported by a JSONReporter. Test is the name of the test (as
+// given by the Name method of testing.TB) and Msg is the message from the
+// Failure with any leading layout removed. The other fields are taken from
+// the Failure.
+type FailureRecord struct {
+	Test       string `json:"test"`
+	ID         string `json:"id"`
+	At         string `json:"at,omitempty"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name,omitempty"`
+	Actual     string `json:"actual"`
+	Expected   string `json:"expected"`
+	GoldenFile string `json:"goldenFile,omitempty"`
+	Msg        string `json:"msg"`
+}
+
+// JSONReporter is a Reporter which writes a FailureRecord for each Failure
+// to an io.Writer or a file. The Failure is also passed to the Next
+// Reporter (or to the TextReporter if Next is nil) which shows it and marks
+// the test as failed. Use the NewJSONReporter or NewJSONFileReporter func
+// to create one.
+type JSONReporter struct {
+	Next Reporter
+
+	mu       sync.Mutex
+	w        io.Writer
+	fileName string
+	file     *os.File
+}
+
+// NewJSONReporter returns a JSONReporter writing to the io.Writer and then
+// passing each Failure to the next Reporter.
+func NewJSONReporter(w io.Writer, next Reporter) *JSONReporter {
+	return &JSONReporter{Next: next, w: w}
+}
+
+// NewJSONFileReporter returns a JSONReporter appending to the named file and
+// then passing each Failure to the next Reporter. The file is not opened
+// until the first Failure is reported and it is closed when the test
+// reporting that Failure completes; it is opened again as needed. If the
+// file cannot be opened the problem is shown with each Failure.
+func NewJSONFileReporter(fileName string, next Reporter) *JSONReporter {
+	return &JSONReporter{Next: next, fileName: fileName}
+}
+
+// Report passes the Failure to the next Reporter and writes the
+// FailureRecord. Any problem writing the record is logged.
+func (jr *JSONReporter) Report(t testing.TB, f Failure) {
+	t.Helper()
+
+	next := jr.Next
+	if next == nil {
+		next = TextReporter{}
+	}
+
+	next.Report(t, f)
+
+	if err := jr.write(t, f); err != nil {
+		t.Logf("\t: Couldn't write the JSON failure record: %s", err)
+	}
+}
+
+// write writes the FailureRecord for the Failure
+func (jr *JSONReporter) write(t testing.TB, f Failure) error {
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+
+	rec, err := json.Marshal(FailureRecord{
+		Test:       t.Name(),
+		ID:         f.ID,
+		At:         f.At,
+		Kind:       f.Kind,
+		Name:       f.Name,
+		Actual:     f.Act,
+		Expected:   f.Exp,
+		GoldenFile: f.File,
+		Msg:        plainMsg(f.Msg),
+	})
+	if err != nil {
+		return err
+	}
+
+	w, err := jr.writer(t)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(rec, '\n'))
+
+	return err
+}
+
+// writer returns the io.Writer to which the records should be written. If
+// the JSONReporter writes to a file which is not open it is opened and
+// will be closed when the test completes.
+func (jr *JSONReporter) writer(t testing.TB) (io.Writer, error) {
+	if jr.fileName == "" || jr.file != nil {
+		return jr.w, nil
+	}
+
+	file, err := os.OpenFile(jr.fileName, //nolint:gosec
+		os.O_APPEND|os.O_CREATE|os.O_WRONLY, pBits)
+	if err != nil {
+		return nil, err
+	}
+
+	jr.file, jr.w = file, file
+
+	t.Cleanup(func() { jr.closeFile(t) })
+
+	return jr.w, nil
+}
+
+// closeFile closes the file to which the records are written, if it is
+// open. Any problem closing the file is logged.
+func (jr *JSONReporter) closeFile(t testing.TB) {
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+
+	if jr.file == nil {
+		return
+	}
+
+	if err := jr.file.Close(); err != nil {
+		t.Logf("\t: Couldn't close the JSON failures file: %s", err)
+	}
+
+	jr.file, jr.w = nil, nil
+}
+
+// defaultReporter returns the Reporter to be used by default. This is a
+// JSONReporter if the JSONFailuresEnvVar environment variable is set and a
+// TextReporter otherwise.
+func defaultReporter() Reporter {
+	if fileName := os.Getenv(JSONFailuresEnvVar); fileName != "" {
+		return NewJSONFileReporter(fileName, nil)
+	}
+
+	return TextReporter{}
+}
@@ -0,0 +1,192 @@
+package testhelper_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+// badWriter is an io.Writer which always fails
+type badWriter struct{}
+
+func (badWriter) Write([]byte) (int, error) {
+	return 0, errors.New("bad writer")
+}
+
+func TestJSONReporter(t *testing.T) {
+	errTC := struct {
+		testhelper.ID
+		testhelper.ExpErr
+	}{
+		ID:     testhelper.MkID("err"),
+		ExpErr: testhelper.MkExpErr("oops"),
+	}
+	gfDir := t.TempDir()
+	gfName := filepath.Join(gfDir, "nonesuch")
+
+	_, gfErr := os.ReadFile(gfName)
+	if !errors.Is(gfErr, fs.ErrNotExist) {
+		t.Fatal("the golden file should not exist, the error was: ", gfErr)
+	}
+
+	testCases := []struct {
+		testhelper.ID
+		f      func(t testing.TB)
+		expRec testhelper.FailureRecord
+	}{
+		{
+			ID: testhelper.MkID("DiffString"),
+			f: func(t testing.TB) {
+				testhelper.DiffString(t, "id", "str", "abc", "abd")
+			},
+			expRec: testhelper.FailureRecord{
+				Test:     t.Name(),
+				ID:       "id",
+				Kind:     "DiffString",
+				Name:     "str",
+				Actual:   `"abc"`,
+				Expected: `"abd"`,
+				Msg:      "str is incorrect",
+			},
+		},
+		{
+			ID: testhelper.MkID("CheckExpErr"),
+			f: func(t testing.TB) {
+				testhelper.CheckExpErr(t, nil, errTC)
+			},
+			expRec: testhelper.FailureRecord{
+				Test:     t.Name(),
+				ID:       errTC.IDStr(),
+				At:       errTC.AtFullName,
+				Kind:     "CheckError",
+				Name:     "error",
+				Expected: "oops",
+				Msg:      "an error was expected but none was returned",
+			},
+		},
+		{
+			ID: testhelper.MkID("GoldenFile"),
+			f: func(t testing.TB) {
+				gfc := testhelper.GoldenFileCfg{DirNames: []string{gfDir}}
+				gfc.Check(t, "id", "nonesuch", []byte("val"))
+			},
+			expRec: testhelper.FailureRecord{
+				Test:       t.Name(),
+				ID:         "id",
+				Kind:       "GoldenFile",
+				Actual:     "val",
+				GoldenFile: gfName,
+				Msg: "Couldn't read the expected value. Error: " +
+					gfErr.Error(),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+
+		ft := &fakeTB{TB: t}
+		testhelper.SetTestReporter(ft,
+			testhelper.NewJSONReporter(&buf, nil))
+
+		tc.f(ft)
+
+		if !ft.Failed() {
+			t.Log(tc.IDStr())
+			t.Error("\t: the TextReporter should have been used as well")
+		}
+
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		if testhelper.DiffInt(t, tc.IDStr(), "record count",
+			len(lines), 1) {
+			continue
+		}
+
+		var rec testhelper.FailureRecord
+		if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+			t.Log(tc.IDStr())
+			t.Errorf("\t: couldn't unmarshal the record: %s", err)
+
+			continue
+		}
+
+		testhelper.CheckValsWithID(t, tc.IDStr(), "record", rec, tc.expRec)
+	}
+}
+
+func TestJSONReporterWriteErr(t *testing.T) {
+	ft := &fakeTB{TB: t}
+	testhelper.SetTestReporter(ft,
+		testhelper.NewJSONReporter(badWriter{}, nil))
+
+	testhelper.DiffBool(ft, "id", "flag", true, false)
+
+	testhelper.ShouldContain(t, "test: bad writer", "log", ft.Logged(),
+		[]string{
+			"flag is incorrect",
+			"Couldn't write the JSON failure record: bad writer",
+		})
+}
+
+func TestJSONFileReporter(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "failures.json")
+	jr := testhelper.NewJSONFileReporter(fileName, nil)
+
+	if _, err := os.Stat(fileName); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatal("the file should not be created before a failure: ", err)
+	}
+
+	for _, name := range []string{"first", "second"} {
+		t.Run(name, func(t *testing.T) {
+			ft := &fakeTB{TB: t}
+			testhelper.SetTestReporter(ft, jr)
+
+			testhelper.DiffInt(ft, name, "count", 1, 2)
+		})
+	}
+
+	content, err := os.ReadFile(fileName) //nolint:gosec
+	if err != nil {
+		t.Fatal("couldn't read the file: ", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	if testhelper.DiffInt(t, "test: file reporter", "record count",
+		len(lines), 2) {
+		return
+	}
+
+	for i, name := range []string{"first", "second"} {
+		var rec testhelper.FailureRecord
+		if err := json.Unmarshal([]byte(lines[i]), &rec); err != nil {
+			t.Fatal("couldn't unmarshal the record: ", err)
+		}
+
+		testhelper.DiffString(t, "test: file reporter", "record ID",
+			rec.ID, name)
+		testhelper.DiffString(t, "test: file reporter", "record test name",
+			rec.Test, t.Name()+"/"+name)
+	}
+}
+
+func TestJSONFileReporterOpenErr(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "missing", "failures.json")
+	ft := &fakeTB{TB: t}
+	testhelper.SetTestReporter(ft,
+		testhelper.NewJSONFileReporter(fileName, nil))
+
+	testhelper.DiffBool(ft, "id", "flag", true, false)
+
+	testhelper.ShouldContain(t, "test: bad file", "log", ft.Logged(),
+		[]string{
+			"flag is incorrect",
+			"Couldn't write the JSON failure record: open " + fileName,
+		})
+}
@@ -0,0 +1,94 @@
+package testhelper
+
+import (
+	"cmp"
+	"reflect"
+	"slices"
+)
+
+// sortedMapKeys returns the keys of the map in a deterministic order
+func sortedMapKeys(m reflect.Value) []reflect.Value {
+	keys := m.MapKeys()
+	slices.SortStableFunc(keys, compareKeys)
+
+	return keys
+}
+
+// compareKeys compares the two values and returns an integer less than, equal
+// to or greater than zero according to whether a should be ordered before,
+// alongside or after b. It is intended for ordering map keys so that they
+// can be reported in a stable order. Values of different types are ordered
+// by the name of their type. The ordering within a type is as follows:
+//
+//   - ints, uints, floats and strings are ordered by value, with any NaN
+//     values coming first
+//   - complex numbers are ordered by their real and then imaginary parts
+//   - false comes before true
+//   - pointers and channels are ordered by their address
+//   - structs and arrays are ordered by their first differing member
+//   - interfaces are ordered with nil first and then by their contents
+func compareKeys(a, b reflect.Value) int { //nolint:cyclop
+	if !a.IsValid() || !b.IsValid() {
+		return cmp.Compare(boolOrder(a.IsValid()), boolOrder(b.IsValid()))
+	}
+
+	if a.Type() != b.Type() {
+		return cmp.Compare(a.Type().String(), b.Type().String())
+	}
+
+	switch a.Kind() { //nolint:exhaustive
+	case reflect.Int, reflect.Int8, reflect.Int16,
+		reflect.Int32, reflect.Int64:
+		return cmp.Compare(a.Int(), b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return cmp.Compare(a.Uint(), b.Uint())
+	case reflect.String:
+		return cmp.Compare(a.String(), b.String())
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(a.Float(), b.Float())
+	case reflect.Complex64, reflect.Complex128:
+		if c := cmp.Compare(real(a.Complex()), real(b.Complex())); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(imag(a.Complex()), imag(b.Complex()))
+	case reflect.Bool:
+		return cmp.Compare(boolOrder(a.Bool()), boolOrder(b.Bool()))
+	case reflect.Pointer, reflect.UnsafePointer, reflect.Chan:
+		return cmp.Compare(a.Pointer(), b.Pointer())
+	case reflect.Struct:
+		for i := range a.NumField() {
+			if c := compareKeys(a.Field(i), b.Field(i)); c != 0 {
+				return c
+			}
+		}
+
+		return 0
+	case reflect.Array:
+		for i := range a.Len() {
+			if c := compareKeys(a.Index(i), b.Index(i)); c != 0 {
+				return c
+			}
+		}
+
+		return 0
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return cmp.Compare(boolOrder(!a.IsNil()), boolOrder(!b.IsNil()))
+		}
+
+		return compareKeys(a.Elem(), b.Elem())
+	}
+
+	return 0
+}
+
+// boolOrder returns an integer which can be used to order bool values
+func boolOrder(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
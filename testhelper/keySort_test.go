@@ -0,0 +1,141 @@
+package testhelper
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestSortedMapKeys(t *testing.T) {
+	type key struct {
+		s string
+		i int
+	}
+
+	testCases := []struct {
+		ID
+		m       any
+		expKeys []string
+	}{
+		{
+			ID:      MkID("ints"),
+			m:       map[int]bool{3: true, -1: true, 10: true, 2: true},
+			expKeys: []string{"-1", "2", "3", "10"},
+		},
+		{
+			ID:      MkID("strings"),
+			m:       map[string]bool{"b": true, "a": true, "B": true},
+			expKeys: []string{"B", "a", "b"},
+		},
+		{
+			ID: MkID("floats"),
+			m: map[float64]bool{
+				2.5: true, math.NaN(): true, math.Inf(-1): true, 0: true,
+			},
+			expKeys: []string{"NaN", "-Inf", "0", "2.5"},
+		},
+		{
+			ID:      MkID("bools"),
+			m:       map[bool]int{true: 1, false: 0},
+			expKeys: []string{"false", "true"},
+		},
+		{
+			ID: MkID("structs"),
+			m: map[key]bool{
+				{s: "b", i: 1}: true,
+				{s: "a", i: 2}: true,
+				{s: "a", i: 1}: true,
+			},
+			expKeys: []string{"{a 1}", "{a 2}", "{b 1}"},
+		},
+		{
+			ID:      MkID("arrays"),
+			m:       map[[2]int]bool{{2, 1}: true, {1, 3}: true, {1, 2}: true},
+			expKeys: []string{"[1 2]", "[1 3]", "[2 1]"},
+		},
+		{
+			ID: MkID("interfaces, mixed types"),
+			m: map[any]bool{
+				"b": true, 2: true, nil: true, "a": true, 1: true, 1.5: true,
+			},
+			expKeys: []string{"<nil>", "1.5", "1", "2", "a", "b"},
+		},
+	}
+
+	for _, tc := range testCases {
+		keys := sortedMapKeys(reflect.ValueOf(tc.m))
+		keyStrs := []string{}
+
+		for _, k := range keys {
+			keyStrs = append(keyStrs, fmt.Sprintf("%v", k))
+		}
+
+		DiffStringSlice(t, tc.IDStr(), "keys", keyStrs, tc.expKeys)
+	}
+}
+
+func TestMapEntries(t *testing.T) {
+	nan := math.NaN()
+
+	testCases := []struct {
+		ID
+		act, exp   map[any]int
+		expEntries []string
+	}{
+		{
+			ID:         MkID("both empty"),
+			expEntries: []string{},
+		},
+		{
+			ID:  MkID("same keys"),
+			act: map[any]int{"b": 1, "a": 2},
+			exp: map[any]int{"a": 3, "b": 4},
+			expEntries: []string{
+				"a: act: 2 exp: 3",
+				"b: act: 1 exp: 4",
+			},
+		},
+		{
+			ID:  MkID("different keys, mixed types"),
+			act: map[any]int{"b": 1, 2: 2},
+			exp: map[any]int{"a": 3, 1: 4, 2: 5},
+			expEntries: []string{
+				"1: exp: 4",
+				"2: act: 2 exp: 5",
+				"a: exp: 3",
+				"b: act: 1",
+			},
+		},
+		{
+			ID:  MkID("NaN keys"),
+			act: map[any]int{nan: 2, 1.0: 1, nan + 1: 1},
+			exp: map[any]int{nan: 3, 1.0: 1},
+			expEntries: []string{
+				"NaN: act: 1",
+				"NaN: act: 2",
+				"NaN: exp: 3",
+				"1: act: 1 exp: 1",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		entries := []string{}
+
+		for _, e := range mapEntries(tc.act, tc.exp) {
+			s := fmt.Sprintf("%v:", e.key)
+			if e.inAct {
+				s += fmt.Sprintf(" act: %d", e.actV)
+			}
+
+			if e.inExp {
+				s += fmt.Sprintf(" exp: %d", e.expV)
+			}
+
+			entries = append(entries, s)
+		}
+
+		DiffStringSlice(t, tc.IDStr(), "entries", entries, tc.expEntries)
+	}
+}
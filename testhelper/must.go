@@ -0,0 +1,398 @@
+package testhelper
+
+// This file holds the Must... variants of the check funcs. Each one makes
+// the same check, and reports any problem in the same way, as the func it
+// is named after but then stops the test by calling t.FailNow. They avoid
+// the need to check the result of the check func when the rest of the test
+// makes no sense if the check fails. Note that, as for t.FailNow, they must
+// be called from the goroutine running the test.
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"golang.org/x/exp/constraints"
+)
+
+// failNowIf calls t.FailNow if a problem was found
+func failNowIf(t testing.TB, problemFound bool) {
+	t.Helper()
+
+	if problemFound {
+		t.FailNow()
+	}
+}
+
+// MustCheckExpErr is as CheckExpErr but calls t.FailNow if the error is not
+// as expected
+func MustCheckExpErr(t testing.TB, err error, tce TestCaseWithErr) {
+	t.Helper()
+	failNowIf(t, !CheckExpErr(t, err, tce))
+}
+
+// MustCheckExpErrWithID is as CheckExpErrWithID but calls t.FailNow if the
+// error is not as expected
+func MustCheckExpErrWithID(t testing.TB, testID string, err error,
+	te TestErr,
+) {
+	t.Helper()
+	failNowIf(t, !CheckExpErrWithID(t, testID, err, te))
+}
+
+// MustCheckError is as CheckError but calls t.FailNow if the error is not
+// as expected
+func MustCheckError(t testing.TB, testID string, err error,
+	expected bool, shouldContain []string,
+) {
+	t.Helper()
+	failNowIf(t, !CheckError(t, testID, err, expected, shouldContain))
+}
+
+// MustCheckExpPanic is as CheckExpPanic but calls t.FailNow if the panic
+// is not as expected
+func MustCheckExpPanic(t testing.TB, panicked bool, panicVal any,
+	tp TestCaseWithPanic,
+) {
+	t.Helper()
+	failNowIf(t, CheckExpPanic(t, panicked, panicVal, tp))
+}
+
+// MustCheckExpPanicWithStack is as CheckExpPanicWithStack but calls
+// t.FailNow if the panic is not as expected
+func MustCheckExpPanicWithStack(t testing.TB, panicked bool, panicVal any,
+	tp TestCaseWithPanic, stackTrace []byte,
+) {
+	t.Helper()
+	failNowIf(t,
+		CheckExpPanicWithStack(t, panicked, panicVal, tp, stackTrace))
+}
+
+// MustCheckExpPanicError is as CheckExpPanicError but calls t.FailNow if
+// the panic is not as expected
+func MustCheckExpPanicError(t testing.TB, panicked bool, panicVal any,
+	tp TestCaseWithPanic,
+) {
+	t.Helper()
+	failNowIf(t, CheckExpPanicError(t, panicked, panicVal, tp))
+}
+
+// MustCheckExpPanicErrorWithStack is as CheckExpPanicErrorWithStack but
+// calls t.FailNow if the panic is not as expected
+func MustCheckExpPanicErrorWithStack(t testing.TB,
+	panicked bool, panicVal any,
+	tp TestCaseWithPanic, stackTrace []byte,
+) {
+	t.Helper()
+	failNowIf(t,
+		CheckExpPanicErrorWithStack(t, panicked, panicVal, tp, stackTrace))
+}
+
+// MustPanicCheckString is as PanicCheckString but calls t.FailNow if the
+// panic is not as expected
+func MustPanicCheckString(t testing.TB, testID string,
+	panicked, panicExpected bool,
+	panicVal any, shouldContain []string,
+) {
+	t.Helper()
+	failNowIf(t, PanicCheckString(t, testID,
+		panicked, panicExpected, panicVal, shouldContain))
+}
+
+// MustPanicCheckStringWithStack is as PanicCheckStringWithStack but calls
+// t.FailNow if the panic is not as expected
+func MustPanicCheckStringWithStack(t testing.TB, testID string,
+	panicked, panicExpected bool,
+	panicVal any, shouldContain []string, stackTrace []byte,
+) {
+	t.Helper()
+	failNowIf(t, PanicCheckStringWithStack(t, testID,
+		panicked, panicExpected, panicVal, shouldContain, stackTrace))
+}
+
+// MustPanicCheckError is as PanicCheckError but calls t.FailNow if the
+// panic is not as expected
+func MustPanicCheckError(t testing.TB, testID string,
+	panicked, panicExpected bool,
+	panicVal any, shouldContain []string,
+) {
+	t.Helper()
+	failNowIf(t, PanicCheckError(t, testID,
+		panicked, panicExpected, panicVal, shouldContain))
+}
+
+// MustPanicCheckErrorWithStack is as PanicCheckErrorWithStack but calls
+// t.FailNow if the panic is not as expected
+func MustPanicCheckErrorWithStack(t testing.TB, testID string,
+	panicked, panicExpected bool,
+	panicVal any, shouldContain []string, stackTrace []byte,
+) {
+	t.Helper()
+	failNowIf(t, PanicCheckErrorWithStack(t, testID,
+		panicked, panicExpected, panicVal, shouldContain, stackTrace))
+}
+
+// MustNotPanic is as ReportUnexpectedPanic but calls t.FailNow if there
+// was a panic
+func MustNotPanic(t testing.TB, testID string,
+	panicked bool, panicVal any, stackTrace []byte,
+) {
+	t.Helper()
+	failNowIf(t,
+		ReportUnexpectedPanic(t, testID, panicked, panicVal, stackTrace))
+}
+
+// MustContain is as ShouldContain but calls t.FailNow if any of the
+// strings are missing
+func MustContain(t testing.TB, testID, desc, act string, exp []string) {
+	t.Helper()
+	failNowIf(t, ShouldContain(t, testID, desc, act, exp))
+}
+
+// MustCheck is as the Check method but calls t.FailNow if the value does
+// not match the contents of the golden file
+func (gfc GoldenFileCfg) MustCheck(t testing.TB, id, gfName string,
+	val []byte,
+) {
+	t.Helper()
+	failNowIf(t, !gfc.Check(t, id, gfName, val))
+}
+
+// MustDiffFloat is as DiffFloat but calls t.FailNow if the values differ
+func MustDiffFloat[T constraints.Float](t testing.TB, id, name string,
+	act, exp, epsilon T,
+) {
+	t.Helper()
+	failNowIf(t, DiffFloat(t, id, name, act, exp, epsilon))
+}
+
+// MustDiffFloatTol is as DiffFloatTol but calls t.FailNow if the values
+// differ
+func MustDiffFloatTol[T constraints.Float](t testing.TB, id, name string,
+	act, exp T, ft FloatTol,
+) {
+	t.Helper()
+	failNowIf(t, DiffFloatTol(t, id, name, act, exp, ft))
+}
+
+// MustDiffComplex is as DiffComplex but calls t.FailNow if the values
+// differ
+func MustDiffComplex[T constraints.Complex](t testing.TB, id, name string,
+	act, exp T, ft FloatTol,
+) {
+	t.Helper()
+	failNowIf(t, DiffComplex(t, id, name, act, exp, ft))
+}
+
+// MustDiffInt is as DiffInt but calls t.FailNow if the values differ
+func MustDiffInt[T constraints.Integer](t testing.TB, id, name string,
+	act, exp T,
+) {
+	t.Helper()
+	failNowIf(t, DiffInt(t, id, name, act, exp))
+}
+
+// MustDiffString is as DiffString but calls t.FailNow if the values differ
+func MustDiffString[S ~string](t testing.TB, id, name string, act, exp S) {
+	t.Helper()
+	failNowIf(t, DiffString(t, id, name, act, exp))
+}
+
+// MustDiffStringContext is as DiffStringContext but calls t.FailNow if the
+// values differ
+func MustDiffStringContext[S ~string](t testing.TB, id, name string,
+	act, exp S, context int,
+) {
+	t.Helper()
+	failNowIf(t, DiffStringContext(t, id, name, act, exp, context))
+}
+
+// MustDiffStringer is as DiffStringer but calls t.FailNow if the values
+// differ
+func MustDiffStringer(t testing.TB, id, name string,
+	actS, expS fmt.Stringer,
+) {
+	t.Helper()
+	failNowIf(t, DiffStringer(t, id, name, actS, expS))
+}
+
+// MustDiffBool is as DiffBool but calls t.FailNow if the values differ
+func MustDiffBool(t testing.TB, id, name string, act, exp bool) {
+	t.Helper()
+	failNowIf(t, DiffBool(t, id, name, act, exp))
+}
+
+// MustDiffTime is as DiffTime but calls t.FailNow if the values differ
+func MustDiffTime(t testing.TB, id, name string, act, exp time.Time) {
+	t.Helper()
+	failNowIf(t, DiffTime(t, id, name, act, exp))
+}
+
+// MustDiff is as the Diff method but calls t.FailNow if the values differ
+func (dtc DiffTimeCfg) MustDiff(t testing.TB, id, name string,
+	act, exp time.Time,
+) {
+	t.Helper()
+	failNowIf(t, dtc.Diff(t, id, name, act, exp))
+}
+
+// MustDiffDuration is as DiffDuration but calls t.FailNow if the values
+// differ by more than the tolerance
+func MustDiffDuration(t testing.TB, id, name string,
+	act, exp, tolerance time.Duration,
+) {
+	t.Helper()
+	failNowIf(t, DiffDuration(t, id, name, act, exp, tolerance))
+}
+
+// MustDiffErr is as DiffErr but calls t.FailNow if the values differ
+func MustDiffErr(t testing.TB, id, name string, act, exp error) {
+	t.Helper()
+	failNowIf(t, DiffErr(t, id, name, act, exp))
+}
+
+// MustDiffErrIs is as DiffErrIs but calls t.FailNow if the error does not
+// match the target
+func MustDiffErrIs(t testing.TB, id, name string, act, target error) {
+	t.Helper()
+	failNowIf(t, DiffErrIs(t, id, name, act, target))
+}
+
+// MustDiffErrAs is as DiffErrAs but calls t.FailNow if the error does not
+// match the type
+func MustDiffErrAs[E error](t testing.TB, id, name string, act error) {
+	t.Helper()
+	failNowIf(t, DiffErrAs[E](t, id, name, act))
+}
+
+// MustDiffErrTree is as DiffErrTree but calls t.FailNow if the error trees
+// differ
+func MustDiffErrTree(t testing.TB, id, name string, act, exp error) {
+	t.Helper()
+	failNowIf(t, DiffErrTree(t, id, name, act, exp))
+}
+
+// MustDiffSlice is as DiffSlice but calls t.FailNow if the values differ
+func MustDiffSlice[C comparable](t testing.TB, id, name string,
+	act, exp []C,
+) {
+	t.Helper()
+	failNowIf(t, DiffSlice(t, id, name, act, exp))
+}
+
+// MustDiffSliceNilness is as DiffSliceNilness but calls t.FailNow if just
+// one of the slices is nil
+func MustDiffSliceNilness[T any](t testing.TB, id, name string,
+	act, exp []T,
+) {
+	t.Helper()
+	failNowIf(t, DiffSliceNilness(t, id, name, act, exp))
+}
+
+// MustDiffFloatSlice is as DiffFloatSlice but calls t.FailNow if the values
+// differ
+func MustDiffFloatSlice[F constraints.Float](t testing.TB, id, name string,
+	act, exp []F, epsilon F,
+) {
+	t.Helper()
+	failNowIf(t, DiffFloatSlice(t, id, name, act, exp, epsilon))
+}
+
+// MustDiffFloatSliceTol is as DiffFloatSliceTol but calls t.FailNow if the
+// values differ
+func MustDiffFloatSliceTol[F constraints.Float](t testing.TB,
+	id, name string, act, exp []F, ft FloatTol,
+) {
+	t.Helper()
+	failNowIf(t, DiffFloatSliceTol(t, id, name, act, exp, ft))
+}
+
+// MustDiffStringSlice is as DiffStringSlice but calls t.FailNow if the
+// values differ
+func MustDiffStringSlice[S ~string](t testing.TB, id, name string,
+	act, exp []S,
+) {
+	t.Helper()
+	failNowIf(t, DiffStringSlice(t, id, name, act, exp))
+}
+
+// MustDiffSliceUnordered is as DiffSliceUnordered but calls t.FailNow if
+// the values differ
+func MustDiffSliceUnordered[C comparable](t testing.TB, id, name string,
+	act, exp []C,
+) {
+	t.Helper()
+	failNowIf(t, DiffSliceUnordered(t, id, name, act, exp))
+}
+
+// MustDiffMap is as DiffMap but calls t.FailNow if the values differ
+func MustDiffMap[K, V comparable](t testing.TB, id, name string,
+	act, exp map[K]V,
+) {
+	t.Helper()
+	failNowIf(t, DiffMap(t, id, name, act, exp))
+}
+
+// MustDiffFloatMap is as DiffFloatMap but calls t.FailNow if the values
+// differ
+func MustDiffFloatMap[K comparable, F constraints.Float](t testing.TB,
+	id, name string, act, exp map[K]F, epsilon F,
+) {
+	t.Helper()
+	failNowIf(t, DiffFloatMap(t, id, name, act, exp, epsilon))
+}
+
+// MustDiffBigInt is as DiffBigInt but calls t.FailNow if the values differ
+func MustDiffBigInt(t testing.TB, id, name string, act, exp *big.Int) {
+	t.Helper()
+	failNowIf(t, DiffBigInt(t, id, name, act, exp))
+}
+
+// MustDiffBigFloat is as DiffBigFloat but calls t.FailNow if the values
+// differ
+func MustDiffBigFloat(t testing.TB, id, name string, act, exp *big.Float) {
+	t.Helper()
+	failNowIf(t, DiffBigFloat(t, id, name, act, exp))
+}
+
+// MustDiffBigRat is as DiffBigRat but calls t.FailNow if the values differ
+func MustDiffBigRat(t testing.TB, id, name string, act, exp *big.Rat) {
+	t.Helper()
+	failNowIf(t, DiffBigRat(t, id, name, act, exp))
+}
+
+// MustCheckVals is as CheckVals but calls t.FailNow if the values differ
+func MustCheckVals(t testing.TB, tc TestCase, name string, act, exp any,
+	ignore ...[]string,
+) {
+	t.Helper()
+	failNowIf(t, CheckVals(t, tc, name, act, exp, ignore...))
+}
+
+// MustCheckValsWithID is as CheckValsWithID but calls t.FailNow if the
+// values differ
+func MustCheckValsWithID(t testing.TB, id, name string, act, exp any,
+	ignore ...[]string,
+) {
+	t.Helper()
+	failNowIf(t, CheckValsWithID(t, id, name, act, exp, ignore...))
+}
+
+// MustCheckVals is as the CheckVals method but calls t.FailNow if the
+// values differ
+func (dvc DiffValsCfg) MustCheckVals(t testing.TB, tc TestCase, name string,
+	act, exp any,
+) {
+	t.Helper()
+	failNowIf(t, dvc.CheckVals(t, tc, name, act, exp))
+}
+
+// MustCheckValsWithID is as the CheckValsWithID method but calls t.FailNow
+// if the values differ
+func (dvc DiffValsCfg) MustCheckValsWithID(t testing.TB, id, name string,
+	act, exp any,
+) {
+	t.Helper()
+	failNowIf(t, dvc.CheckValsWithID(t, id, name, act, exp))
+}
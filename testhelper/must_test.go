@@ -0,0 +1,143 @@
+package testhelper_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestMust(t *testing.T) {
+	errOops := errors.New("oops")
+
+	testCases := []struct {
+		testhelper.ID
+		f          func(t testing.TB)
+		expFailNow bool
+	}{
+		{
+			ID: testhelper.MkID("MustDiffInt - same"),
+			f: func(t testing.TB) {
+				testhelper.MustDiffInt(t, "id", "val", 1, 1)
+			},
+		},
+		{
+			ID: testhelper.MkID("MustDiffInt - differs"),
+			f: func(t testing.TB) {
+				testhelper.MustDiffInt(t, "id", "val", 1, 2)
+			},
+			expFailNow: true,
+		},
+		{
+			ID: testhelper.MkID("MustCheckError - as expected"),
+			f: func(t testing.TB) {
+				testhelper.MustCheckError(t, "id", errOops, true,
+					[]string{"oops"})
+			},
+		},
+		{
+			ID: testhelper.MkID("MustCheckError - unexpected error"),
+			f: func(t testing.TB) {
+				testhelper.MustCheckError(t, "id", errOops, false, nil)
+			},
+			expFailNow: true,
+		},
+		{
+			ID: testhelper.MkID("MustPanicCheckString - unexpected panic"),
+			f: func(t testing.TB) {
+				testhelper.MustPanicCheckString(t, "id", true, false,
+					"panic", nil)
+			},
+			expFailNow: true,
+		},
+		{
+			ID: testhelper.MkID(
+				"MustPanicCheckStringWithStack - as expected"),
+			f: func(t testing.TB) {
+				testhelper.MustPanicCheckStringWithStack(t, "id",
+					true, true, "panic", []string{"panic"}, []byte("stack"))
+			},
+		},
+		{
+			ID: testhelper.MkID(
+				"MustPanicCheckErrorWithStack - unexpected panic"),
+			f: func(t testing.TB) {
+				testhelper.MustPanicCheckErrorWithStack(t, "id",
+					true, false, errOops, nil, []byte("stack"))
+			},
+			expFailNow: true,
+		},
+		{
+			ID: testhelper.MkID("MustDiffStringContext - differs"),
+			f: func(t testing.TB) {
+				testhelper.MustDiffStringContext(t, "id", "str",
+					"a\nb\n", "a\nc\n", 1)
+			},
+			expFailNow: true,
+		},
+		{
+			ID: testhelper.MkID("MustContain - missing"),
+			f: func(t testing.TB) {
+				testhelper.MustContain(t, "id", "str", "abc",
+					[]string{"x"})
+			},
+			expFailNow: true,
+		},
+		{
+			ID: testhelper.MkID("MustDiffErrIs - matches"),
+			f: func(t testing.TB) {
+				testhelper.MustDiffErrIs(t, "id", "err", errOops, errOops)
+			},
+		},
+		{
+			ID: testhelper.MkID("MustCheckVals - differs"),
+			f: func(t testing.TB) {
+				testhelper.MustCheckValsWithID(t, "id", "val",
+					[]int{1}, []int{2})
+			},
+			expFailNow: true,
+		},
+		{
+			ID: testhelper.MkID("DiffValsCfg.MustCheckValsWithID - same"),
+			f: func(t testing.TB) {
+				testhelper.DiffValsCfg{}.MustCheckValsWithID(t, "id", "val",
+					[]int{1}, []int{1})
+			},
+		},
+		{
+			ID: testhelper.MkID("MustCheck - no golden file"),
+			f: func(t testing.TB) {
+				gfc := testhelper.GoldenFileCfg{
+					DirNames: []string{"testdata", "nonesuch"},
+				}
+				gfc.MustCheck(t, "id", "x", []byte("val"))
+			},
+			expFailNow: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		ft := &fakeTB{TB: t}
+		reachedEnd := false
+
+		panicked, panicVal := testhelper.PanicSafe(func() {
+			tc.f(ft)
+			reachedEnd = true
+		})
+
+		//nolint:errorlint
+		if panicked && panicVal != testhelper.ErrFakeFailNow {
+			t.Log(tc.IDStr())
+			t.Errorf("\t: unexpected panic: %v", panicVal)
+
+			continue
+		}
+
+		testhelper.DiffBool(t, tc.IDStr(), "FailNow called",
+			panicked, tc.expFailNow)
+		testhelper.DiffBool(t, tc.IDStr(), "reached the end",
+			reachedEnd, !tc.expFailNow)
+		testhelper.DiffBool(t, tc.IDStr(), "failed",
+			ft.Failed(), tc.expFailNow)
+	}
+}
@@ -0,0 +1,41 @@
+package testhelper
+
+import "fmt"
+
+// NilPolicy controls how nil values are compared with empty or zero
+// values. Values which have been converted to and from some external form
+// (such as JSON) will often have nil values replaced with empty values, or
+// vice versa, and the NilPolicy allows you to choose whether this matters.
+type NilPolicy int
+
+// These are the different NilPolicy values.
+const (
+	// NilEqualsEmpty treats a nil slice as equal to an empty slice and a
+	// nil map as equal to an empty map. A nil pointer is only equal to
+	// another nil pointer. This is the default behaviour and is also how
+	// the slice helper funcs, such as DiffSlice and StringSliceDiff,
+	// behave.
+	NilEqualsEmpty NilPolicy = iota
+	// NilDiffersFromEmpty treats nil slices and maps as different from
+	// empty ones. Use the DiffSliceNilness func to get this behaviour from
+	// the slice helper funcs.
+	NilDiffersFromEmpty
+	// NilEqualsZero treats nil slices and maps in the same way as
+	// NilEqualsEmpty and also treats a nil pointer as equal to a pointer to
+	// the zero value of the type pointed to.
+	NilEqualsZero
+)
+
+// String returns a string describing the NilPolicy
+func (np NilPolicy) String() string {
+	switch np {
+	case NilEqualsEmpty:
+		return "NilEqualsEmpty"
+	case NilDiffersFromEmpty:
+		return "NilDiffersFromEmpty"
+	case NilEqualsZero:
+		return "NilEqualsZero"
+	}
+
+	return fmt.Sprintf("NilPolicy(%d)", int(np))
+}
@@ -73,150 +73,152 @@ func PanicSafe(f func()) (panicked bool, panicVal any) {
 // CheckExpPanic calls PanicCheckString using the details from the test case to
 // supply the parameters
 func CheckExpPanic(
-	t *testing.T, panicked bool, panicVal any,
+	t testing.TB, panicked bool, panicVal any,
 	tp TestCaseWithPanic,
 ) bool {
 	t.Helper()
 
-	return PanicCheckString(t, tp.IDStr(),
-		panicked, tp.PanicExpected(),
-		panicVal, tp.PanicShldCont())
+	return checkPanic(t, tp.IDStr(), tcLocation(tp), "PanicCheckString",
+		panicked, panicVal, nil,
+		badPanicString(panicked, tp.PanicExpected(),
+			panicVal, tp.PanicShldCont()))
 }
 
 // CheckExpPanicWithStack calls PanicCheckStringWithStack using the details from
 // the test case to supply the parameters
 func CheckExpPanicWithStack(
-	t *testing.T, panicked bool, panicVal any,
+	t testing.TB, panicked bool, panicVal any,
 	tp TestCaseWithPanic, stackTrace []byte,
 ) bool {
 	t.Helper()
 
-	return PanicCheckStringWithStack(t, tp.IDStr(),
-		panicked, tp.PanicExpected(),
-		panicVal, tp.PanicShldCont(),
-		stackTrace)
+	return checkPanic(t, tp.IDStr(), tcLocation(tp), "PanicCheckString",
+		panicked, panicVal, stackTrace,
+		badPanicString(panicked, tp.PanicExpected(),
+			panicVal, tp.PanicShldCont()))
 }
 
 // CheckExpPanicError calls PanicCheckError using the details from the test
 // case to supply the parameters
-func CheckExpPanicError(t *testing.T, panicked bool, panicVal any,
+func CheckExpPanicError(t testing.TB, panicked bool, panicVal any,
 	tp TestCaseWithPanic,
 ) bool {
 	t.Helper()
 
-	return PanicCheckError(t, tp.IDStr(),
-		panicked, tp.PanicExpected(),
-		panicVal, tp.PanicShldCont())
+	return checkPanic(t, tp.IDStr(), tcLocation(tp), "PanicCheckError",
+		panicked, panicVal, nil,
+		badPanicError(panicked, tp.PanicExpected(),
+			panicVal, tp.PanicShldCont()))
 }
 
 // CheckExpPanicErrorWithStack calls PanicCheckErrorWithStack using the
 // details from the test case to supply the parameters
-func CheckExpPanicErrorWithStack(t *testing.T,
+func CheckExpPanicErrorWithStack(t testing.TB,
 	panicked bool, panicVal any,
 	tp TestCaseWithPanic, stackTrace []byte,
 ) bool {
 	t.Helper()
 
-	return PanicCheckErrorWithStack(t, tp.IDStr(),
-		panicked, tp.PanicExpected(),
-		panicVal, tp.PanicShldCont(),
-		stackTrace)
+	return checkPanic(t, tp.IDStr(), tcLocation(tp), "PanicCheckError",
+		panicked, panicVal, stackTrace,
+		badPanicError(panicked, tp.PanicExpected(),
+			panicVal, tp.PanicShldCont()))
 }
 
 // PanicCheckString tests the panic value (which should be a string) against
 // the passed values. It will report an error if the panic status is
 // unexpected.
-func PanicCheckString(t *testing.T, testID string,
+func PanicCheckString(t testing.TB, testID string,
 	panicked, panicExpected bool,
 	panicVal any, shouldContain []string,
 ) bool {
 	t.Helper()
 
-	msgs := badPanicString(panicked, panicExpected, panicVal, shouldContain)
-	if len(msgs) > 0 {
-		t.Log(testID)
-		showPanicMsgs(t, panicked, panicVal, msgs)
-	}
-
-	return len(msgs) > 0
+	return checkPanic(t, testID, "", "PanicCheckString",
+		panicked, panicVal, nil,
+		badPanicString(panicked, panicExpected, panicVal, shouldContain))
 }
 
 // PanicCheckStringWithStack tests the panic value (which should be a string)
 // against the passed values. A stack trace should also be passed which will
 // be printed if the panic is not as expected and a panic was seen
-func PanicCheckStringWithStack(t *testing.T, testID string,
+func PanicCheckStringWithStack(t testing.TB, testID string,
 	panicked, panicExpected bool,
 	panicVal any, shouldContain []string, stackTrace []byte,
 ) bool {
 	t.Helper()
 
-	msgs := badPanicString(panicked, panicExpected, panicVal, shouldContain)
-	if len(msgs) > 0 {
-		t.Log(testID)
-
-		if panicked {
-			t.Log(string(stackTrace))
-		}
-
-		showPanicMsgs(t, panicked, panicVal, msgs)
-	}
-
-	return len(msgs) > 0
+	return checkPanic(t, testID, "", "PanicCheckString",
+		panicked, panicVal, stackTrace,
+		badPanicString(panicked, panicExpected, panicVal, shouldContain))
 }
 
 // PanicCheckError tests the panic value (which should be an error) against
 // the passed values. It will report an error if the panic status is
 // unexpected.
-func PanicCheckError(t *testing.T, testID string,
+func PanicCheckError(t testing.TB, testID string,
 	panicked, panicExpected bool,
 	panicVal any, shouldContain []string,
 ) bool {
 	t.Helper()
 
-	msgs := badPanicError(panicked, panicExpected, panicVal, shouldContain)
-	if len(msgs) > 0 {
-		t.Log(testID)
-		showPanicMsgs(t, panicked, panicVal, msgs)
-	}
-
-	return len(msgs) > 0
+	return checkPanic(t, testID, "", "PanicCheckError",
+		panicked, panicVal, nil,
+		badPanicError(panicked, panicExpected, panicVal, shouldContain))
 }
 
 // PanicCheckErrorWithStack tests the panic value (which should be an error)
 // against the passed values. A stack trace should also be passed which will
 // be printed if the panic is not as expected and a panic was seen
-func PanicCheckErrorWithStack(t *testing.T, testID string,
+func PanicCheckErrorWithStack(t testing.TB, testID string,
 	panicked, panicExpected bool,
 	panicVal any, shouldContain []string, stackTrace []byte,
 ) bool {
 	t.Helper()
 
-	msgs := badPanicError(panicked, panicExpected, panicVal, shouldContain)
-	if len(msgs) > 0 {
-		t.Log(testID)
+	return checkPanic(t, testID, "", "PanicCheckError",
+		panicked, panicVal, stackTrace,
+		badPanicError(panicked, panicExpected, panicVal, shouldContain))
+}
 
-		if panicked {
-			t.Log(string(stackTrace))
-		}
+// checkPanic reports the problems found with the panic, if there are
+// any. The location (at) of the test case is given if it is known. If
+// there was a panic and the stack trace is not nil it will be shown. It
+// returns true if there were any problems, false otherwise.
+func checkPanic(t testing.TB, testID, at, kind string,
+	panicked bool, panicVal any, stackTrace []byte, msgs []string,
+) bool {
+	t.Helper()
 
-		showPanicMsgs(t, panicked, panicVal, msgs)
+	if len(msgs) == 0 {
+		return false
 	}
 
-	return len(msgs) > 0
+	f := newFailure(testID, kind, "panic")
+	f.At = at
+
+	if panicked && stackTrace != nil {
+		f.log(string(stackTrace))
+	}
+
+	showPanicMsgs(t, f, panicked, panicVal, msgs)
+
+	return true
 }
 
 // ReportUnexpectedPanic will check if panicked is true and will report the
 // unexpected panic if true. it returns the panicked value
-func ReportUnexpectedPanic(t *testing.T, testID string,
+func ReportUnexpectedPanic(t testing.TB, testID string,
 	panicked bool, panicVal any, stackTrace []byte,
 ) bool {
 	t.Helper()
 
 	if panicked {
-		t.Log(testID)
-		t.Logf("\t: panic: %v", panicVal)
-		t.Log("\t: At:", string(stackTrace))
-		t.Error("\t: An unexpected panic was seen")
+		f := newFailure(testID, "ReportUnexpectedPanic", "panic")
+		f.Act = failureVal(panicVal)
+		f.logf("\t: panic: %v", panicVal)
+		f.log("\t: At:", string(stackTrace))
+		f.report(t, "\t: An unexpected panic was seen")
 	}
 
 	return panicked
@@ -292,23 +294,27 @@ func badPanic(panicked, panicExpected bool) []string {
 	return []string{"badPanic has been called unexpectedly"}
 }
 
-// showPanicMsgs reports the problems found with the panic
-func showPanicMsgs(t *testing.T, panicked bool, pv any, msgs []string) {
+// showPanicMsgs adds the problems found with the panic to the Failure and
+// reports it
+func showPanicMsgs(t testing.TB, f *Failure,
+	panicked bool, pv any, msgs []string,
+) {
 	t.Helper()
 
 	if len(msgs) > 0 {
 		if panicked {
-			t.Log("\t: Panic value:")
-			t.Logf("\t\t%v", pv)
+			f.Act = failureVal(pv)
+			f.log("\t: Panic value:")
+			f.logf("\t\t%v", pv)
 		}
 
 		intro := "\t: "
 
 		for _, msg := range msgs {
-			t.Log(intro + msg)
+			f.log(intro + msg)
 			intro = "\t\t"
 		}
 
-		t.Error("\t: Bad Panic")
+		f.report(t, "\t: Bad Panic")
 	}
 }
@@ -0,0 +1,304 @@
+package testhelper
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// patternElemKind records the kind of a single element of a PathPattern
+type patternElemKind int
+
+const (
+	patField patternElemKind = iota
+	patAnyField
+	patIndex
+	patStrKey
+	patAnyIdxOrKey
+	patAnyDepth
+)
+
+// patternElem represents a single element of a PathPattern
+type patternElem struct {
+	kind patternElemKind
+	name string
+	idx  int
+}
+
+// matches returns true if the PathElem is matched by the patternElem. It
+// should not be called for a patAnyDepth patternElem.
+func (pe patternElem) matches(elem PathElem) bool {
+	switch pe.kind {
+	case patField:
+		return elem.Kind == PathField && elem.Name == pe.name
+	case patAnyField:
+		return elem.Kind == PathField
+	case patIndex:
+		if elem.Kind == PathIndex {
+			return elem.Index == pe.idx
+		}
+
+		return elem.Kind == PathMapKey && keyIsInt(elem.Key, pe.idx)
+	case patStrKey:
+		return elem.Kind == PathMapKey &&
+			elem.Key.IsValid() &&
+			elem.Key.Kind() == reflect.String &&
+			elem.Key.String() == pe.name
+	case patAnyIdxOrKey:
+		return elem.Kind == PathIndex || elem.Kind == PathMapKey
+	case patAnyDepth:
+	}
+
+	return false
+}
+
+// keyIsInt returns true if the key is an integer value equal to i
+func keyIsInt(k reflect.Value, i int) bool {
+	if !k.IsValid() {
+		return false
+	}
+
+	switch k.Kind() { //nolint:exhaustive
+	case reflect.Int, reflect.Int8, reflect.Int16,
+		reflect.Int32, reflect.Int64:
+		return k.Int() == int64(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return i >= 0 && k.Uint() == uint64(i)
+	}
+
+	return false
+}
+
+// PathPattern is a parsed pattern which can be matched against the Path to
+// a value being compared by DiffVals. Use the ParsePathPattern func to
+// create one.
+//
+// A pattern is a sequence of elements. Each element matches one step in
+// the Path except for the "**" element which can match any number of
+// steps. The elements are:
+//
+//	name    matches the struct field called "name"
+//	*       matches any struct field
+//	**      matches any sequence of zero or more steps
+//	[N]     matches index N of a slice or array or the map key N
+//	["s"]   matches the map key "s" (given as a Go quoted string)
+//	[*]     matches any slice or array index or any map key
+//
+// Field elements must be separated from the preceding element by a dot
+// ('.'), bracketed elements need no separator. Pointer dereferences,
+// interface unwrapping and transformations (see the AddTransformer func)
+// are ignored when matching. So, for instance,
+//
+//	Items[*].UpdatedAt
+//	Meta["requestID"]
+//	**.ID
+//
+// will match, respectively, the UpdatedAt field of any entry in the Items
+// slice, the "requestID" entry in the Meta map and any field called ID at
+// any depth.
+type PathPattern struct {
+	pattern string
+	elems   []patternElem
+}
+
+// String returns the pattern from which the PathPattern was parsed
+func (pp PathPattern) String() string {
+	return pp.pattern
+}
+
+// Matches returns true if the Path is matched by the PathPattern.
+func (pp PathPattern) Matches(p Path) bool {
+	steps := make(Path, 0, len(p))
+
+	for _, pe := range p {
+		if pe.Kind == PathPtrDeref || pe.Kind == PathIfaceUnwrap ||
+			pe.Kind == PathTransform {
+			continue
+		}
+
+		steps = append(steps, pe)
+	}
+
+	return matchElems(pp.elems, steps)
+}
+
+// matchElems returns true if the pattern elements match the path
+func matchElems(pes []patternElem, p Path) bool {
+	if len(pes) == 0 {
+		return len(p) == 0
+	}
+
+	if pes[0].kind == patAnyDepth {
+		for i := 0; i <= len(p); i++ {
+			if matchElems(pes[1:], p[i:]) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if len(p) == 0 || !pes[0].matches(p[0]) {
+		return false
+	}
+
+	return matchElems(pes[1:], p[1:])
+}
+
+// errBadPathPattern is returned (wrapped) for any path pattern which cannot
+// be parsed.
+var errBadPathPattern = errors.New("bad path pattern")
+
+// badPattern returns an error describing the problem with the pattern
+func badPattern(pattern string, offset int, problem string) error {
+	return fmt.Errorf("%w: %q: %s (at offset %d)",
+		errBadPathPattern, pattern, problem, offset)
+}
+
+// ParsePathPattern parses the string and returns the corresponding
+// PathPattern. It returns a non-nil error if the string cannot be
+// parsed. See the PathPattern type for a description of the syntax.
+func ParsePathPattern(s string) (PathPattern, error) {
+	pp := PathPattern{pattern: s}
+
+	if s == "" {
+		return pp, badPattern(s, 0, "the pattern is empty")
+	}
+
+	for i := 0; i < len(s); {
+		var (
+			pe  patternElem
+			err error
+		)
+
+		switch {
+		case s[i] == '[':
+			pe, i, err = parseBracketElem(s, i)
+		case s[i] == '.' && i > 0:
+			pe, i, err = parseFieldElem(s, i+1)
+		case i == 0:
+			pe, i, err = parseFieldElem(s, i)
+		default:
+			err = badPattern(s, i, "expected '.' or '['")
+		}
+
+		if err != nil {
+			return pp, err
+		}
+
+		pp.elems = append(pp.elems, pe)
+	}
+
+	return pp, nil
+}
+
+// MustParsePathPattern parses the string and returns the corresponding
+// PathPattern. It panics if the string cannot be parsed.
+func MustParsePathPattern(s string) PathPattern {
+	pp, err := ParsePathPattern(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return pp
+}
+
+// parseFieldElem parses a field name (or "*" or "**") starting at the
+// offset. It returns the element, the offset of the next character and any
+// error.
+func parseFieldElem(s string, start int) (patternElem, int, error) {
+	end := start
+	for end < len(s) && s[end] != '.' && s[end] != '[' {
+		end++
+	}
+
+	name := s[start:end]
+
+	switch name {
+	case "":
+		return patternElem{}, end, badPattern(s, start, "missing field name")
+	case "*":
+		return patternElem{kind: patAnyField}, end, nil
+	case "**":
+		return patternElem{kind: patAnyDepth}, end, nil
+	}
+
+	for i, r := range name {
+		if r == '_' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r)) {
+			continue
+		}
+
+		return patternElem{}, end,
+			badPattern(s, start+i, "bad character in field name: "+
+				strconv.QuoteRune(r))
+	}
+
+	return patternElem{kind: patField, name: name}, end, nil
+}
+
+// parseBracketElem parses a bracketed element starting at the offset (which
+// should be the offset of the opening '['). It returns the element, the
+// offset of the next character and any error.
+func parseBracketElem(s string, start int) (patternElem, int, error) {
+	contentStart := start + 1
+
+	if strings.HasPrefix(s[contentStart:], `"`) {
+		return parseStrKeyElem(s, start)
+	}
+
+	closeIdx := strings.IndexByte(s[contentStart:], ']')
+	if closeIdx < 0 {
+		return patternElem{}, len(s), badPattern(s, start, "missing ']'")
+	}
+
+	content := s[contentStart : contentStart+closeIdx]
+	next := contentStart + closeIdx + 1
+
+	if content == "*" {
+		return patternElem{kind: patAnyIdxOrKey}, next, nil
+	}
+
+	idx, err := strconv.Atoi(content)
+	if err != nil {
+		return patternElem{}, next,
+			badPattern(s, contentStart,
+				"the index must be '*', an integer or a quoted string,"+
+					" not "+strconv.Quote(content))
+	}
+
+	return patternElem{kind: patIndex, idx: idx}, next, nil
+}
+
+// parseStrKeyElem parses a bracketed, quoted string starting at the offset
+// (which should be the offset of the opening '['). It returns the element,
+// the offset of the next character and any error.
+func parseStrKeyElem(s string, start int) (patternElem, int, error) {
+	quoteStart := start + 1
+
+	for i := quoteStart + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			key, err := strconv.Unquote(s[quoteStart : i+1])
+			if err != nil {
+				return patternElem{}, i + 1,
+					badPattern(s, quoteStart, "bad quoted string")
+			}
+
+			if i+1 >= len(s) || s[i+1] != ']' {
+				return patternElem{}, i + 1,
+					badPattern(s, i+1, "missing ']'")
+			}
+
+			return patternElem{kind: patStrKey, name: key}, i + 2, nil
+		}
+	}
+
+	return patternElem{}, len(s),
+		badPattern(s, quoteStart, "unterminated quoted string")
+}
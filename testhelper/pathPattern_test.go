@@ -0,0 +1,210 @@
+package testhelper_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestParsePathPattern(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		pattern string
+	}{
+		{
+			ID:      testhelper.MkID("good: single field"),
+			pattern: "Name",
+		},
+		{
+			ID:      testhelper.MkID("good: all element kinds"),
+			pattern: `**.Items[*].*[3]["a \"key\""].UpdatedAt`,
+		},
+		{
+			ID:      testhelper.MkID("good: starts with an index"),
+			pattern: "[2].Name",
+		},
+		{
+			ID:      testhelper.MkID("bad: empty"),
+			ExpErr:  testhelper.MkExpErr("the pattern is empty"),
+			pattern: "",
+		},
+		{
+			ID:      testhelper.MkID("bad: starts with a dot"),
+			ExpErr:  testhelper.MkExpErr("missing field name (at offset 0)"),
+			pattern: ".Name",
+		},
+		{
+			ID:      testhelper.MkID("bad: double dot"),
+			ExpErr:  testhelper.MkExpErr("missing field name (at offset 2)"),
+			pattern: "A..B",
+		},
+		{
+			ID: testhelper.MkID("bad: bad field name"),
+			ExpErr: testhelper.MkExpErr(
+				`bad character in field name: '-' (at offset 3)`),
+			pattern: "Bad-Name",
+		},
+		{
+			ID:      testhelper.MkID("bad: unclosed bracket"),
+			ExpErr:  testhelper.MkExpErr("missing ']' (at offset 1)"),
+			pattern: "A[1",
+		},
+		{
+			ID: testhelper.MkID("bad: unquoted key"),
+			ExpErr: testhelper.MkExpErr(
+				"the index must be '*', an integer or a quoted string,",
+				`not "key"`),
+			pattern: "A[key]",
+		},
+		{
+			ID:      testhelper.MkID("bad: unterminated string"),
+			ExpErr:  testhelper.MkExpErr("unterminated quoted string"),
+			pattern: `A["key]`,
+		},
+		{
+			ID:      testhelper.MkID("bad: no bracket after string"),
+			ExpErr:  testhelper.MkExpErr("missing ']' (at offset 7)"),
+			pattern: `A["key"`,
+		},
+		{
+			ID:      testhelper.MkID("bad: no dot after bracket"),
+			ExpErr:  testhelper.MkExpErr("expected '.' or '[' (at offset 4)"),
+			pattern: `A[1]B`,
+		},
+	}
+
+	for _, tc := range testCases {
+		pp, err := testhelper.ParsePathPattern(tc.pattern)
+		if testhelper.CheckExpErr(t, err, tc) && err == nil {
+			testhelper.DiffString(t, tc.IDStr(), "pattern",
+				pp.String(), tc.pattern)
+		}
+	}
+}
+
+func TestPathPatternMatches(t *testing.T) {
+	field := func(name string) testhelper.PathElem {
+		return testhelper.PathElem{Kind: testhelper.PathField, Name: name}
+	}
+	idx := func(i int) testhelper.PathElem {
+		return testhelper.PathElem{Kind: testhelper.PathIndex, Index: i}
+	}
+	key := func(k any) testhelper.PathElem {
+		return testhelper.PathElem{
+			Kind: testhelper.PathMapKey,
+			Key:  reflect.ValueOf(k),
+		}
+	}
+	deref := testhelper.PathElem{Kind: testhelper.PathPtrDeref}
+
+	testCases := []struct {
+		testhelper.ID
+		pattern  string
+		path     testhelper.Path
+		expMatch bool
+	}{
+		{
+			ID:       testhelper.MkID("field: match"),
+			pattern:  "A.B",
+			path:     testhelper.Path{field("A"), deref, field("B")},
+			expMatch: true,
+		},
+		{
+			ID:      testhelper.MkID("field: too short"),
+			pattern: "A.B",
+			path:    testhelper.Path{field("A")},
+		},
+		{
+			ID:      testhelper.MkID("field: too long"),
+			pattern: "A.B",
+			path:    testhelper.Path{field("A"), field("B"), field("C")},
+		},
+		{
+			ID:      testhelper.MkID("any index"),
+			pattern: "Items[*].UpdatedAt",
+			path: testhelper.Path{
+				field("Items"), idx(7), field("UpdatedAt"),
+			},
+			expMatch: true,
+		},
+		{
+			ID:      testhelper.MkID("any key"),
+			pattern: "Items[*].UpdatedAt",
+			path: testhelper.Path{
+				field("Items"), key(7), field("UpdatedAt"),
+			},
+			expMatch: true,
+		},
+		{
+			ID:       testhelper.MkID("specific index"),
+			pattern:  "Items[7]",
+			path:     testhelper.Path{field("Items"), idx(7)},
+			expMatch: true,
+		},
+		{
+			ID:       testhelper.MkID("specific int key"),
+			pattern:  "Items[7]",
+			path:     testhelper.Path{field("Items"), key(uint8(7))},
+			expMatch: true,
+		},
+		{
+			ID:      testhelper.MkID("wrong index"),
+			pattern: "Items[7]",
+			path:    testhelper.Path{field("Items"), idx(6)},
+		},
+		{
+			ID:       testhelper.MkID("string key"),
+			pattern:  `Meta["requestID"]`,
+			path:     testhelper.Path{field("Meta"), key("requestID")},
+			expMatch: true,
+		},
+		{
+			ID:      testhelper.MkID("wrong string key"),
+			pattern: `Meta["requestID"]`,
+			path:    testhelper.Path{field("Meta"), key("request")},
+		},
+		{
+			ID:      testhelper.MkID("string key, index in path"),
+			pattern: `Meta["1"]`,
+			path:    testhelper.Path{field("Meta"), idx(1)},
+		},
+		{
+			ID:       testhelper.MkID("any depth: at top"),
+			pattern:  "**.ID",
+			path:     testhelper.Path{field("ID")},
+			expMatch: true,
+		},
+		{
+			ID:      testhelper.MkID("any depth: below the match"),
+			pattern: "**.ID",
+			path:    testhelper.Path{field("ID"), field("X")},
+		},
+		{
+			ID:      testhelper.MkID("any depth: deep"),
+			pattern: "**.ID",
+			path: testhelper.Path{
+				field("A"), idx(1), key("k"), deref, field("ID"),
+			},
+			expMatch: true,
+		},
+		{
+			ID:       testhelper.MkID("any field"),
+			pattern:  "*.ID",
+			path:     testhelper.Path{field("A"), field("ID")},
+			expMatch: true,
+		},
+		{
+			ID:      testhelper.MkID("any field, not an index"),
+			pattern: "*.ID",
+			path:    testhelper.Path{idx(0), field("ID")},
+		},
+	}
+
+	for _, tc := range testCases {
+		pp := testhelper.MustParsePathPattern(tc.pattern)
+		testhelper.DiffBool(t, tc.IDStr(), tc.pattern+" matches "+
+			tc.path.String(), pp.Matches(tc.path), tc.expMatch)
+	}
+}
@@ -0,0 +1,228 @@
+package testhelper
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Failure describes a problem found by one of the check funcs (such as
+// DiffInt, CheckError or GoldenFileCfg.Check). It is passed to a Reporter
+// which is responsible for showing it and for marking the test as failed.
+//
+//	ID is the ID of the test. It may be empty if no ID was given.
+//
+//	At is the location where the test case was made, as given by the
+//	AtFullName of its ID. It is only set by the funcs which are passed the
+//	test case (such as CheckExpErr) rather than just the test ID.
+//
+//	Kind is the name of the check which found the problem, for instance
+//	"DiffInt" or "GoldenFile".
+//
+//	Name is the name of the value being checked, if any.
+//
+//	Act and Exp are the actual and expected values, formatted as strings
+//	(usually by the DefaultValPrinter). They are empty if the check has no
+//	single value to show.
+//
+//	File is the name of the golden file, if any.
+//
+//	Details holds the lines describing the problem and Msg is the error
+//	message which summarises it. Notes holds any further lines to be shown
+//	after the message, such as how to update a golden file. These are all
+//	formatted as they are shown by the TextReporter.
+type Failure struct {
+	ID   string
+	At   string
+	Kind string
+	Name string
+	Act  string
+	Exp  string
+	File string
+
+	Details []string
+	Msg     string
+	Notes   []string
+}
+
+// newFailure returns a Failure with the ID, Kind and Name set
+func newFailure(id, kind, name string) *Failure {
+	return &Failure{ID: id, Kind: kind, Name: name}
+}
+
+// newValFailure returns a Failure with the ID, Kind and Name set and with
+// the actual and expected values formatted by failureVal
+func newValFailure(id, kind, name string, act, exp any) *Failure {
+	return newStrFailure(id, kind, name, failureVal(act), failureVal(exp))
+}
+
+// newStrFailure returns a Failure with the ID, Kind and Name set and with
+// the actual and expected values, which have already been formatted
+func newStrFailure(id, kind, name, act, exp string) *Failure {
+	f := newFailure(id, kind, name)
+	f.Act, f.Exp = act, exp
+
+	return f
+}
+
+// failureVal returns the value formatted for a Failure. Errors are shown
+// with their type and message and other values are shown by the
+// DefaultValPrinter.
+func failureVal(v any) string {
+	if err, ok := v.(error); ok {
+		return errNodeStr(err)
+	}
+
+	return PrettyPrint(v)
+}
+
+// log adds the values, formatted as for fmt.Sprintln, to the details
+func (f *Failure) log(args ...any) {
+	f.Details = append(f.Details, fmt.Sprintln(args...))
+}
+
+// logf adds the values, formatted as for fmt.Sprintf, to the details
+func (f *Failure) logf(format string, args ...any) {
+	f.Details = append(f.Details, fmt.Sprintf(format, args...))
+}
+
+// note adds the values, formatted as for fmt.Sprintf, to the notes
+func (f *Failure) note(format string, args ...any) {
+	f.Notes = append(f.Notes, fmt.Sprintf(format, args...))
+}
+
+// report sets the message, formatted as for fmt.Sprintf, and passes the
+// Failure to the Reporter for the test
+func (f *Failure) report(t testing.TB, format string, args ...any) {
+	t.Helper()
+
+	f.Msg = fmt.Sprintf(format, args...)
+	reporterFor(t).Report(t, *f)
+}
+
+// plainMsg returns the message with the leading layout (as used by the
+// TextReporter) and any surrounding white space removed
+func plainMsg(msg string) string {
+	msg = strings.TrimSpace(msg)
+	msg = strings.TrimPrefix(msg, ":")
+
+	return strings.TrimSpace(msg)
+}
+
+// Reporter is the interface used to report the problems found by the check
+// funcs. The Report method should show the Failure and mark the test as
+// failed (for instance, by calling t.Error). It should call t.Helper so
+// that the location reported is that of the test rather than of the
+// Reporter.
+//
+// The TextReporter is used by default (or a JSONReporter, see the
+// JSONFailuresEnvVar). Use the SetReporter func to change the Reporter for
+// all the tests or the SetTestReporter func to change it for a single
+// test.
+type Reporter interface {
+	Report(t testing.TB, f Failure)
+}
+
+// TextReporter reports each Failure as lines of text logged through the
+// testing.TB: the test ID, the details, the message and then any notes. It
+// is the default Reporter.
+type TextReporter struct{}
+
+// Report logs the Failure and marks the test as failed
+func (TextReporter) Report(t testing.TB, f Failure) {
+	t.Helper()
+
+	if f.ID != "" {
+		t.Log(f.ID)
+	}
+
+	for _, d := range f.Details {
+		t.Logf("%s", d)
+	}
+
+	t.Errorf("%s", f.Msg)
+
+	for _, n := range f.Notes {
+		t.Logf("%s", n)
+	}
+}
+
+// initialReporter is the Reporter used until SetReporter is called
+var initialReporter = defaultReporter()
+
+// reporters holds the Reporters to be used, both the default and those
+// set for individual tests
+var reporters = struct {
+	mu      sync.Mutex
+	dflt    Reporter
+	perTest map[testing.TB]Reporter
+}{
+	dflt:    initialReporter,
+	perTest: map[testing.TB]Reporter{},
+}
+
+// SetReporter sets the Reporter used by all the tests (apart from those
+// with their own Reporter, see SetTestReporter) and returns the previous
+// value. If the Reporter is nil the initial Reporter is restored; this is
+// the TextReporter unless the JSONFailuresEnvVar is set.
+func SetReporter(r Reporter) Reporter {
+	if r == nil {
+		r = initialReporter
+	}
+
+	reporters.mu.Lock()
+	defer reporters.mu.Unlock()
+
+	prev := reporters.dflt
+	reporters.dflt = r
+
+	return prev
+}
+
+// SetTestReporter sets the Reporter used for the given test. It is removed
+// when the test completes. The Reporter is not used for any subtests; set
+// it for each subtest as required. If the Reporter is nil the test will
+// use the Reporter set by SetReporter.
+func SetTestReporter(t testing.TB, r Reporter) {
+	t.Helper()
+
+	if !reflect.TypeOf(t).Comparable() {
+		panic(fmt.Errorf("SetTestReporter: the testing.TB (a %T)"+
+			" is not comparable", t))
+	}
+
+	reporters.mu.Lock()
+	defer reporters.mu.Unlock()
+
+	if r == nil {
+		delete(reporters.perTest, t)
+		return
+	}
+
+	if _, ok := reporters.perTest[t]; !ok {
+		t.Cleanup(func() {
+			reporters.mu.Lock()
+			defer reporters.mu.Unlock()
+
+			delete(reporters.perTest, t)
+		})
+	}
+
+	reporters.perTest[t] = r
+}
+
+// reporterFor returns the Reporter to be used for the test
+func reporterFor(t testing.TB) Reporter {
+	reporters.mu.Lock()
+	defer reporters.mu.Unlock()
+
+	if reflect.TypeOf(t).Comparable() {
+		if r, ok := reporters.perTest[t]; ok {
+			return r
+		}
+	}
+
+	return reporters.dflt
+}
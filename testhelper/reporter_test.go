@@ -0,0 +1,179 @@
+package testhelper_test
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+// fakeTB is the testing.TB, declared in the internal tests, which records
+// the messages logged rather than passing them to a real test
+type fakeTB = testhelper.FakeTB
+
+// recReporter is a Reporter which records the Failures rather than
+// reporting them. It may be used by parallel tests.
+type recReporter struct {
+	mu       sync.Mutex
+	failures []testhelper.Failure
+}
+
+func (rr *recReporter) Report(_ testing.TB, f testhelper.Failure) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	rr.failures = append(rr.failures, f)
+}
+
+func TestSetTestReporter(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		f        func(t testing.TB) bool
+		expFails []testhelper.Failure
+	}{
+		{
+			ID: testhelper.MkID("DiffInt"),
+			f: func(t testing.TB) bool {
+				return testhelper.DiffInt(t, "id", "val", 1, 2)
+			},
+			expFails: []testhelper.Failure{
+				{
+					ID:   "id",
+					Kind: "DiffInt",
+					Name: "val",
+					Act:  "1",
+					Exp:  "2",
+					Details: []string{
+						"\t: expected val:     2\n",
+						"\t:   actual val:     1\n",
+						"\t:         diff:     1\n",
+					},
+					Msg: "\t: val is incorrect\n",
+				},
+			},
+		},
+		{
+			ID: testhelper.MkID("DiffStringSlice"),
+			f: func(t testing.TB) bool {
+				return testhelper.DiffStringSlice(t, "id", "strs",
+					[]string{"a", "b"}, []string{"a"})
+			},
+			expFails: []testhelper.Failure{
+				{
+					ID:   "id",
+					Kind: "DiffStringSlice",
+					Name: "strs",
+					Act:  `[]string{"a", "b"}`,
+					Exp:  `[]string{"a"}`,
+					Details: []string{
+						"\t: expected strs length:    1\n",
+						"\t:   actual strs length:    2\n",
+						"\t: unexpected strs [1]: \"b\"\n",
+						"\t: 1 difference found\n",
+					},
+					Msg: "\t: strs is incorrect\n",
+				},
+			},
+		},
+		{
+			ID: testhelper.MkID("CheckError"),
+			f: func(t testing.TB) bool {
+				return !testhelper.CheckError(t, "id", nil, true,
+					[]string{"oops"})
+			},
+			expFails: []testhelper.Failure{
+				{
+					ID:   "id",
+					Kind: "CheckError",
+					Name: "error",
+					Exp:  "oops",
+					Msg:  "\t: an error was expected but none was returned",
+				},
+			},
+		},
+		{
+			ID: testhelper.MkID("PanicCheckError"),
+			f: func(t testing.TB) bool {
+				return testhelper.PanicCheckError(t, "id", true, false,
+					errors.New("oops"), nil)
+			},
+			expFails: []testhelper.Failure{
+				{
+					ID:   "id",
+					Kind: "PanicCheckError",
+					Name: "panic",
+					Act:  `*errors.errorString: "oops"`,
+					Details: []string{
+						"\t: Panic value:\n",
+						"\t\toops",
+						"\t: there was an unexpected panic\n",
+					},
+					Msg: "\t: Bad Panic",
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		ft := &fakeTB{TB: t}
+		rr := &recReporter{}
+		testhelper.SetTestReporter(ft, rr)
+
+		if !tc.f(ft) {
+			t.Log(tc.IDStr())
+			t.Error("\t: the helper should have reported a problem")
+		}
+
+		if ft.Failed() {
+			t.Log(tc.IDStr())
+			t.Error("\t: the Reporter should have been used instead" +
+				" of the TextReporter")
+		}
+
+		testhelper.CheckValsWithID(t, tc.IDStr(), "failures",
+			rr.failures, tc.expFails)
+	}
+}
+
+func TestSetReporter(t *testing.T) {
+	rr := &recReporter{}
+	prev := testhelper.SetReporter(rr)
+
+	defer testhelper.SetReporter(prev)
+
+	if _, ok := prev.(testhelper.TextReporter); !ok &&
+		os.Getenv(testhelper.JSONFailuresEnvVar) == "" {
+		t.Errorf("the default Reporter should be a TextReporter, not a %T",
+			prev)
+	}
+
+	ft := &fakeTB{TB: t}
+	testhelper.DiffBool(ft, "id", "flag", true, false)
+
+	if ft.Failed() {
+		t.Error("the Reporter should have been used instead" +
+			" of the TextReporter")
+	}
+
+	// the Reporter is still replaced so the count is checked directly
+	if len(rr.failures) != 1 {
+		t.Errorf("the Reporter should have recorded 1 failure, not %d",
+			len(rr.failures))
+	}
+
+	if got := testhelper.SetReporter(nil); got != rr {
+		t.Errorf("SetReporter should have returned the recReporter, not %v",
+			got)
+	}
+
+	testhelper.SetReporter(nil)
+
+	ft = &fakeTB{TB: t}
+	testhelper.DiffBool(ft, "id", "flag", true, false)
+
+	if !ft.Failed() {
+		t.Error("the TextReporter should have been restored")
+	}
+}
@@ -0,0 +1,115 @@
+package testhelper
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+// RunTable runs each of the test cases as a subtest, named by its IDStr
+// method. For each test case it calls fn, checks any panic or error and
+// then, if there was neither, calls check to check the result. The check
+// func may be nil if there is nothing more to check.
+//
+// If the test case has an ExpPanic embedded (it satisfies the
+// TestCaseWithPanic interface) any panic is checked with the
+// CheckExpPanicWithStack func, otherwise any panic is reported as
+// unexpected. Similarly, if the test case has an ExpErr embedded (it
+// satisfies the TestCaseWithErr interface) the error is checked with the
+// CheckExpErr func, otherwise any error is reported as unexpected. The
+// check func is only called if there was no panic and no error.
+//
+// For instance,
+//
+//	testCases := []struct {
+//	    testhelper.ID
+//	    testhelper.ExpErr
+//	    s      string
+//	    expVal int
+//	}{ ... }
+//
+//	testhelper.RunTable(t, testCases,
+//	    func(tc tcType) (int, error) { return strconv.Atoi(tc.s) },
+//	    func(t *testing.T, tc tcType, v int) {
+//	        testhelper.DiffInt(t, tc.IDStr(), "value", v, tc.expVal)
+//	    })
+func RunTable[TC TestCase, R any](t *testing.T, cases []TC,
+	fn func(TC) (R, error),
+	check func(t *testing.T, tc TC, r R),
+) {
+	t.Helper()
+
+	for _, tc := range cases {
+		t.Run(tc.IDStr(), func(t *testing.T) {
+			t.Helper()
+			runCase(t, tc, fn, check)
+		})
+	}
+}
+
+// RunTableParallel runs the test cases in the same way as RunTable but
+// each subtest calls t.Parallel so that the test cases are run in parallel
+// with one another. The fn and check funcs must be safe to call
+// concurrently.
+func RunTableParallel[TC TestCase, R any](t *testing.T, cases []TC,
+	fn func(TC) (R, error),
+	check func(t *testing.T, tc TC, r R),
+) {
+	t.Helper()
+
+	for _, tc := range cases {
+		t.Run(tc.IDStr(), func(t *testing.T) {
+			t.Helper()
+			t.Parallel()
+			runCase(t, tc, fn, check)
+		})
+	}
+}
+
+// runCase runs a single test case for RunTable and RunTableParallel
+func runCase[TC TestCase, R any](t *testing.T, tc TC,
+	fn func(TC) (R, error),
+	check func(t *testing.T, tc TC, r R),
+) {
+	t.Helper()
+
+	r, err, panicked, panicVal, stackTrace := callCase(tc, fn)
+
+	if tp, ok := any(tc).(TestCaseWithPanic); ok {
+		if CheckExpPanicWithStack(t, panicked, panicVal, tp, stackTrace) ||
+			panicked {
+			return
+		}
+	} else if ReportUnexpectedPanic(t, tc.IDStr(),
+		panicked, panicVal, stackTrace) {
+		return
+	}
+
+	if te, ok := any(tc).(TestCaseWithErr); ok {
+		if !CheckExpErr(t, err, te) || err != nil {
+			return
+		}
+	} else if !CheckError(t, tc.IDStr(), err, false, nil) {
+		return
+	}
+
+	if check != nil {
+		check(t, tc, r)
+	}
+}
+
+// callCase calls fn for the test case and returns its results. If it
+// panics then panicked is set to true and the panic value and the stack
+// trace are returned.
+func callCase[TC TestCase, R any](tc TC, fn func(TC) (R, error)) (
+	r R, err error, panicked bool, panicVal any, stackTrace []byte,
+) {
+	defer func() {
+		if p := recover(); p != nil {
+			panicked, panicVal, stackTrace = true, p, debug.Stack()
+		}
+	}()
+
+	r, err = fn(tc)
+
+	return r, err, panicked, panicVal, stackTrace
+}
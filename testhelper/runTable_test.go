@@ -0,0 +1,250 @@
+package testhelper_test
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+type runTableTC struct {
+	testhelper.ID
+	testhelper.ExpErr
+	testhelper.ExpPanic
+	s       string
+	expVal  int
+	expFail string
+}
+
+// atoi returns the integer value of the test case string. It panics if the
+// string is "panic"
+func atoi(tc runTableTC) (int, error) {
+	if tc.s == "panic" {
+		panic("bad value")
+	}
+
+	return strconv.Atoi(tc.s)
+}
+
+// recordFailures calls f with the Reporter replaced by one which records
+// the failures so that the subtests run by RunTable do not fail. It
+// returns the test IDs and kinds of the recorded failures. The Reporter is
+// restored before it returns so that the results can be checked.
+func recordFailures(f func()) []string {
+	rr := &recReporter{}
+	prev := testhelper.SetReporter(rr)
+
+	f()
+
+	testhelper.SetReporter(prev)
+
+	kinds := []string{}
+	for _, f := range rr.failures {
+		kinds = append(kinds, f.ID+": "+f.Kind)
+	}
+
+	return kinds
+}
+
+func TestRunTable(t *testing.T) {
+	testCases := []runTableTC{
+		{
+			ID:     testhelper.MkID("good"),
+			s:      "42",
+			expVal: 42,
+		},
+		{
+			ID:     testhelper.MkID("bad"),
+			ExpErr: testhelper.MkExpErr(`parsing "x": invalid syntax`),
+			s:      "x",
+		},
+		{
+			ID:       testhelper.MkID("panic"),
+			ExpPanic: testhelper.MkExpPanic("bad value"),
+			s:        "panic",
+		},
+		{
+			ID:       testhelper.MkID("expected panic not seen"),
+			ExpPanic: testhelper.MkExpPanic("bad value"),
+			s:        "42",
+			expFail:  "PanicCheckString",
+		},
+		{
+			ID:      testhelper.MkID("unexpected panic"),
+			s:       "panic",
+			expFail: "PanicCheckString",
+		},
+		{
+			ID:      testhelper.MkID("wrong error"),
+			ExpErr:  testhelper.MkExpErr("oops"),
+			s:       "x",
+			expFail: "ShouldContain",
+		},
+		{
+			ID:      testhelper.MkID("unexpected error"),
+			s:       "x",
+			expFail: "CheckError",
+		},
+	}
+
+	expFails := []string{}
+
+	for _, tc := range testCases {
+		if tc.expFail != "" {
+			expFails = append(expFails, tc.IDStr()+": "+tc.expFail)
+		}
+	}
+
+	for _, run := range []struct {
+		name string
+		f    func(*testing.T, []runTableTC, func(runTableTC) (int, error),
+			func(*testing.T, runTableTC, int))
+	}{
+		{name: "RunTable", f: testhelper.RunTable[runTableTC, int]},
+		{
+			name: "RunTableParallel",
+			f:    testhelper.RunTableParallel[runTableTC, int],
+		},
+	} {
+		var checkCount atomic.Int32
+
+		fails := recordFailures(func() {
+			t.Run(run.name, func(t *testing.T) {
+				run.f(t, testCases, atoi,
+					func(t *testing.T, tc runTableTC, v int) {
+						t.Helper()
+						checkCount.Add(1)
+						testhelper.DiffInt(t, tc.IDStr(), "value",
+							v, tc.expVal)
+					})
+			})
+		})
+
+		testhelper.DiffInt(t, "test: "+run.name, "check calls",
+			checkCount.Load(), 1)
+		testhelper.DiffSliceUnordered(t, "test: "+run.name, "failures",
+			fails, expFails)
+	}
+}
+
+func TestRunTableIDs(t *testing.T) {
+	testCases := []testhelper.ID{
+		testhelper.MkID("no error"),
+		testhelper.MkID("unexpected error"),
+		testhelper.MkID("unexpected panic"),
+	}
+
+	expFails := []string{
+		testCases[1].IDStr() + ": CheckError",
+		testCases[2].IDStr() + ": ReportUnexpectedPanic",
+	}
+
+	checkCount := 0
+
+	fails := recordFailures(func() {
+		testhelper.RunTable(t, testCases,
+			func(tc testhelper.ID) (bool, error) {
+				switch tc.Name {
+				case "unexpected error":
+					return false, errors.New("oops")
+				case "unexpected panic":
+					panic("bad value")
+				}
+
+				return true, nil
+			},
+			func(*testing.T, testhelper.ID, bool) { checkCount++ })
+	})
+
+	testhelper.DiffInt(t, "test: RunTable", "check calls", checkCount, 1)
+	testhelper.DiffSliceUnordered(t, "test: RunTable", "failures",
+		fails, expFails)
+}
+
+func TestRunTableNoChecks(t *testing.T) {
+	testCases := []testhelper.ID{
+		testhelper.MkID("no error"),
+	}
+
+	testhelper.RunTable(t, testCases,
+		func(testhelper.ID) (bool, error) { return true, nil },
+		nil)
+}
+
+func TestRunTableNoCheckAfterErr(t *testing.T) {
+	testCases := []runTableTC{
+		{
+			ID:     testhelper.MkID("expected error"),
+			ExpErr: testhelper.MkExpErr("oops"),
+		},
+	}
+
+	testhelper.RunTable(t, testCases,
+		func(runTableTC) (int, error) { return 0, errors.New("oops") },
+		func(t *testing.T, _ runTableTC, _ int) {
+			t.Error("the check func should not be called after an error")
+		})
+}
+
+func TestRunTableSubtests(t *testing.T) {
+	testCases := []testhelper.ID{
+		testhelper.MkID("first"),
+		testhelper.MkID("second"),
+		testhelper.MkID("third"),
+	}
+
+	for _, run := range []struct {
+		name string
+		f    func(*testing.T, []testhelper.ID,
+			func(testhelper.ID) (bool, error),
+			func(*testing.T, testhelper.ID, bool))
+		expParallel bool
+	}{
+		{name: "RunTable", f: testhelper.RunTable[testhelper.ID, bool]},
+		{
+			name:        "RunTableParallel",
+			f:           testhelper.RunTableParallel[testhelper.ID, bool],
+			expParallel: true,
+		},
+	} {
+		var (
+			mu       sync.Mutex
+			names    []string
+			returned atomic.Bool
+		)
+
+		t.Run(run.name, func(t *testing.T) {
+			// parallel subtests are only run once this func has returned
+			defer returned.Store(true)
+
+			run.f(t, testCases,
+				func(testhelper.ID) (bool, error) {
+					return returned.Load(), nil
+				},
+				func(t *testing.T, _ testhelper.ID, afterReturn bool) {
+					testhelper.DiffBool(t, "test: "+run.name,
+						"run after the table func returned",
+						afterReturn, run.expParallel)
+
+					mu.Lock()
+					defer mu.Unlock()
+
+					names = append(names, t.Name())
+				})
+		})
+
+		expNames := []string{}
+		for _, tc := range testCases {
+			expNames = append(expNames,
+				t.Name()+"/"+run.name+"/"+
+					strings.ReplaceAll(tc.IDStr(), " ", "_"))
+		}
+
+		testhelper.DiffSliceUnordered(t, "test: "+run.name, "subtest names",
+			names, expNames)
+	}
+}
@@ -9,21 +9,32 @@ import (
 // the 'exp' argument and reports an error if it does not. The desc
 // parameter is used to describe the string being checked. It returns true if
 // a problem was found, false otherwise.
-func ShouldContain(t *testing.T, testID, desc, act string, exp []string) bool {
+func ShouldContain(t testing.TB, testID, desc, act string, exp []string) bool {
+	t.Helper()
+
+	return shouldContainAt(t, testID, "", desc, act, exp)
+}
+
+// shouldContainAt checks the string as described for ShouldContain. The
+// location (at) of the test case is given if it is known.
+func shouldContainAt(t testing.TB, testID, at, desc, act string, exp []string,
+) bool {
 	t.Helper()
 
 	missing := missingParts(act, exp)
 	if len(missing) > 0 {
-		t.Log(testID)
-		t.Logf("\t: an unexpected %s value was seen:", desc)
-		t.Log("\t\t" + act)
-		t.Log("\t: it should contain:")
+		f := newFailure(testID, "ShouldContain", desc)
+		f.At = at
+		f.Act, f.Exp = act, strings.Join(exp, ", ")
+		f.logf("\t: an unexpected %s value was seen:", desc)
+		f.log("\t\t" + act)
+		f.log("\t: it should contain:")
 
 		for _, part := range missing {
-			t.Log("\t\t" + part)
+			f.log("\t\t" + part)
 		}
 
-		t.Error("\t: Parts of the string were missing")
+		f.report(t, "\t: Parts of the string were missing")
 
 		return true
 	}
@@ -0,0 +1,272 @@
+package testhelper
+
+import (
+	"fmt"
+	"slices"
+)
+
+// EditOp describes the kind of change recorded in a SliceEdit
+type EditOp int
+
+// These are the different kinds of EditOp
+const (
+	// EditInsert records an element in the actual slice which is not in
+	// the expected slice
+	EditInsert EditOp = iota
+	// EditDelete records an element in the expected slice which is not in
+	// the actual slice
+	EditDelete
+	// EditChange records an element in the expected slice which has been
+	// replaced by a different element in the actual slice
+	EditChange
+)
+
+// String returns a string describing the EditOp
+func (op EditOp) String() string {
+	switch op {
+	case EditInsert:
+		return "insert"
+	case EditDelete:
+		return "delete"
+	case EditChange:
+		return "change"
+	}
+
+	return fmt.Sprintf("EditOp(%d)", int(op))
+}
+
+// SliceEdit records a single difference between an actual and an expected
+// slice. For an EditChange both ActIdx and ExpIdx give the indexes of the
+// differing elements. For an EditInsert the ActIdx gives the index of the
+// unexpected element and the ExpIdx gives the position in the expected
+// slice at which it would be inserted. For an EditDelete the ExpIdx gives
+// the index of the missing element and the ActIdx gives the position in
+// the actual slice at which it is missing.
+type SliceEdit struct {
+	Op     EditOp
+	ActIdx int
+	ExpIdx int
+}
+
+// String returns a string describing the SliceEdit
+func (se SliceEdit) String() string {
+	switch se.Op {
+	case EditInsert:
+		return fmt.Sprintf("insert actual[%d]", se.ActIdx)
+	case EditDelete:
+		return fmt.Sprintf("delete expected[%d]", se.ExpIdx)
+	case EditChange:
+	}
+
+	return fmt.Sprintf("%s expected[%d] to actual[%d]",
+		se.Op, se.ExpIdx, se.ActIdx)
+}
+
+// SliceEdits returns the edits needed to transform the expected slice into
+// the actual slice. The edits are found from a longest common subsequence
+// of the slices (using the Myers diff algorithm) so that an element
+// inserted into or deleted from the middle of a slice is reported as such
+// rather than as a change to every subsequent element. Adjacent deletions
+// and insertions are reported as changes. The edits are returned in order
+// of increasing index. If the slices are the same an empty slice is
+// returned.
+func SliceEdits[T comparable](act, exp []T) []SliceEdit {
+	return SliceEditsFunc(act, exp, func(a, e T) bool { return a == e })
+}
+
+// SliceEditsFunc returns the edits needed to transform the expected slice
+// into the actual slice in the same way as SliceEdits but using the
+// supplied func to decide if two elements are equal.
+func SliceEditsFunc[T any](act, exp []T, eq func(a, e T) bool) []SliceEdit {
+	return editScript(len(act), len(exp),
+		func(ai, ei int) bool { return eq(act[ai], exp[ei]) })
+}
+
+// maxEditDistance is the largest number of insertions and deletions that
+// the editScript will search for. Beyond this the memory used would be
+// excessive and so the slices are simply compared element by element.
+const maxEditDistance = 2000
+
+// editOp is a single step in the path through the edit graph
+type editOp struct {
+	keep   bool
+	op     EditOp
+	actIdx int
+	expIdx int
+}
+
+// editScript returns the SliceEdits needed to transform a slice of length
+// expLen into a slice of length actLen. The eq func reports whether the
+// elements at the given actual and expected indexes are equal.
+func editScript(actLen, expLen int, eq func(ai, ei int) bool) []SliceEdit {
+	ops, ok := myersOps(actLen, expLen, eq)
+	if !ok {
+		ops = indexOps(actLen, expLen, eq)
+	}
+
+	return mergeEditOps(ops)
+}
+
+// myersOps returns the shortest sequence of editOps transforming the
+// expected slice into the actual slice. It returns false if the edit
+// distance is more than maxEditDistance.
+//
+// This follows the notation used by Myers in "An O(ND) Difference
+// Algorithm and Its Variations"; x indexes the expected slice and y
+// indexes the actual slice. The furthest reaching x for each diagonal, k,
+// is recorded in v and the state of v before each step is kept so that
+// the path can be recovered afterwards.
+func myersOps(actLen, expLen int, eq func(ai, ei int) bool) ([]editOp, bool) {
+	maxD := min(actLen+expLen, maxEditDistance)
+	offset := maxD + 1
+	v := make([]int, 2*offset+1)
+	trace := make([][]int, 0)
+
+	for d := 0; d <= maxD; d++ {
+		trace = append(trace, slices.Clone(v[offset-d-1:offset+d+2]))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+
+			y := x - k
+			for x < expLen && y < actLen && eq(y, x) {
+				x++
+				y++
+			}
+
+			v[offset+k] = x
+
+			if x >= expLen && y >= actLen {
+				return myersBacktrack(trace, actLen, expLen), true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// myersBacktrack recovers the editOps from the trace of the furthest
+// reaching points recorded by myersOps. Each entry in the trace holds the
+// values for the diagonals from -(d+1) to d+1.
+func myersBacktrack(trace [][]int, actLen, expLen int) []editOp {
+	ops := make([]editOp, 0, actLen+expLen)
+	x, y := expLen, actLen
+
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		vOffset := d + 1
+		k := x - y
+
+		prevK := k - 1
+		if k == -d || (k != d && v[vOffset+k-1] < v[vOffset+k+1]) {
+			prevK = k + 1
+		}
+
+		prevX := v[vOffset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, editOp{keep: true, actIdx: y, expIdx: x})
+		}
+
+		if prevK == k+1 {
+			ops = append(ops,
+				editOp{op: EditInsert, actIdx: prevY, expIdx: prevX})
+		} else {
+			ops = append(ops,
+				editOp{op: EditDelete, actIdx: prevY, expIdx: prevX})
+		}
+
+		x, y = prevX, prevY
+	}
+
+	for x > 0 && y > 0 {
+		x--
+		y--
+		ops = append(ops, editOp{keep: true, actIdx: y, expIdx: x})
+	}
+
+	slices.Reverse(ops)
+
+	return ops
+}
+
+// indexOps returns the editOps found by comparing the elements index by
+// index. Any extra elements in the longer slice are reported as inserted or
+// deleted.
+func indexOps(actLen, expLen int, eq func(ai, ei int) bool) []editOp {
+	ops := make([]editOp, 0, max(actLen, expLen))
+
+	for i := range min(actLen, expLen) {
+		if eq(i, i) {
+			ops = append(ops, editOp{keep: true, actIdx: i, expIdx: i})
+		} else {
+			ops = append(ops, editOp{op: EditDelete, actIdx: i, expIdx: i},
+				editOp{op: EditInsert, actIdx: i, expIdx: i})
+		}
+	}
+
+	for i := expLen; i < actLen; i++ {
+		ops = append(ops, editOp{op: EditInsert, actIdx: i, expIdx: expLen})
+	}
+
+	for i := actLen; i < expLen; i++ {
+		ops = append(ops, editOp{op: EditDelete, actIdx: actLen, expIdx: i})
+	}
+
+	return ops
+}
+
+// mergeEditOps converts the editOps into SliceEdits. The deletions and
+// insertions in each run of edits between kept elements are paired off and
+// reported as changes. Any left over are reported as deletions or
+// insertions.
+func mergeEditOps(ops []editOp) []SliceEdit {
+	edits := []SliceEdit{}
+
+	for i := 0; i < len(ops); {
+		if ops[i].keep {
+			i++
+			continue
+		}
+
+		var dels, ins []editOp
+
+		for ; i < len(ops) && !ops[i].keep; i++ {
+			if ops[i].op == EditDelete {
+				dels = append(dels, ops[i])
+			} else {
+				ins = append(ins, ops[i])
+			}
+		}
+
+		pairs := min(len(dels), len(ins))
+
+		for j := range pairs {
+			edits = append(edits, SliceEdit{
+				Op:     EditChange,
+				ActIdx: ins[j].actIdx,
+				ExpIdx: dels[j].expIdx,
+			})
+		}
+
+		for _, d := range dels[pairs:] {
+			edits = append(edits,
+				SliceEdit{Op: EditDelete, ActIdx: d.actIdx, ExpIdx: d.expIdx})
+		}
+
+		for _, in := range ins[pairs:] {
+			edits = append(edits,
+				SliceEdit{Op: EditInsert, ActIdx: in.actIdx, ExpIdx: in.expIdx})
+		}
+	}
+
+	return edits
+}
@@ -0,0 +1,133 @@
+package testhelper_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestSliceEdits(t *testing.T) {
+	ins := func(a, e int) testhelper.SliceEdit {
+		return testhelper.SliceEdit{
+			Op: testhelper.EditInsert, ActIdx: a, ExpIdx: e,
+		}
+	}
+	del := func(a, e int) testhelper.SliceEdit {
+		return testhelper.SliceEdit{
+			Op: testhelper.EditDelete, ActIdx: a, ExpIdx: e,
+		}
+	}
+	chg := func(a, e int) testhelper.SliceEdit {
+		return testhelper.SliceEdit{
+			Op: testhelper.EditChange, ActIdx: a, ExpIdx: e,
+		}
+	}
+
+	testCases := []struct {
+		testhelper.ID
+		act, exp string
+		expEdits []testhelper.SliceEdit
+	}{
+		{
+			ID: testhelper.MkID("both empty"),
+		},
+		{
+			ID:  testhelper.MkID("same"),
+			act: "abc",
+			exp: "abc",
+		},
+		{
+			ID:       testhelper.MkID("actual empty"),
+			exp:      "ab",
+			expEdits: []testhelper.SliceEdit{del(0, 0), del(0, 1)},
+		},
+		{
+			ID:       testhelper.MkID("expected empty"),
+			act:      "ab",
+			expEdits: []testhelper.SliceEdit{ins(0, 0), ins(1, 0)},
+		},
+		{
+			ID:       testhelper.MkID("insertion in the middle"),
+			act:      "abXcdef",
+			exp:      "abcdef",
+			expEdits: []testhelper.SliceEdit{ins(2, 2)},
+		},
+		{
+			ID:       testhelper.MkID("deletion in the middle"),
+			act:      "abdef",
+			exp:      "abcdef",
+			expEdits: []testhelper.SliceEdit{del(2, 2)},
+		},
+		{
+			ID:       testhelper.MkID("change in the middle"),
+			act:      "abXdef",
+			exp:      "abcdef",
+			expEdits: []testhelper.SliceEdit{chg(2, 2)},
+		},
+		{
+			ID:  testhelper.MkID("several edits"),
+			act: "XabdeYYf",
+			exp: "abcdef",
+			expEdits: []testhelper.SliceEdit{
+				ins(0, 0), del(3, 2), ins(5, 5), ins(6, 5),
+			},
+		},
+		{
+			ID:  testhelper.MkID("changes and insertion"),
+			act: "aXYZd",
+			exp: "abcd",
+			expEdits: []testhelper.SliceEdit{
+				chg(1, 1), chg(2, 2), ins(3, 3),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		act := strings.Split(tc.act, "")
+		exp := strings.Split(tc.exp, "")
+
+		edits := testhelper.SliceEdits(act, exp)
+		testhelper.DiffSlice(t, tc.IDStr(), "edits", edits, tc.expEdits)
+	}
+}
+
+func TestSliceEditsFunc(t *testing.T) {
+	act := []string{"A", "b", "X", "C"}
+	exp := []string{"a", "B", "c"}
+
+	edits := testhelper.SliceEditsFunc(act, exp, strings.EqualFold)
+	testhelper.DiffSlice(t, "case-insensitive", "edits", edits,
+		[]testhelper.SliceEdit{
+			{Op: testhelper.EditInsert, ActIdx: 2, ExpIdx: 2},
+		})
+}
+
+func TestSliceEditsLargeDistance(t *testing.T) {
+	const sliceLen = 1500
+
+	act := make([]int, sliceLen)
+	exp := make([]int, sliceLen)
+
+	for i := range exp {
+		exp[i] = 1
+	}
+
+	edits := testhelper.SliceEdits(act, exp)
+	if testhelper.DiffInt(t, "large edit distance", "edit count",
+		len(edits), sliceLen) {
+		return
+	}
+
+	for i, e := range edits {
+		expEdit := testhelper.SliceEdit{
+			Op: testhelper.EditChange, ActIdx: i, ExpIdx: i,
+		}
+		if e != expEdit {
+			t.Log("large edit distance")
+			t.Errorf("\t: bad edit[%d]: %s", i, e)
+
+			break
+		}
+	}
+}
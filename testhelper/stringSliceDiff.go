@@ -2,7 +2,8 @@ package testhelper
 
 // StringSliceDiff will compare two slices of strings for equality. If they
 // are of different lengths they are taken to be different. A nil slice and
-// an empty slice are taken to be the same.
+// an empty slice are taken to be the same (see NilEqualsEmpty). Use the
+// SliceEdits func to find out how the slices differ.
 func StringSliceDiff(a, b []string) bool {
 	if len(a) != len(b) {
 		return true
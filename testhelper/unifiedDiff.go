@@ -0,0 +1,179 @@
+package testhelper
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DiffContextLines gives the default number of unchanged lines shown before
+// and after each group of changed lines when the differences between
+// multi-line strings are reported. Use the DiffStringContext func or the
+// ContextLines field of a DiffValsCfg to show a different number.
+const DiffContextLines = 3
+
+// noNewlineMarker is shown after a line which is not terminated by a
+// newline
+const noNewlineMarker = `\ No newline at end of file`
+
+// UnifiedDiff returns the differences between the expected and the actual
+// strings in the unified diff format. Lines only in the expected string are
+// shown with a leading '-', lines only in the actual string with a leading
+// '+' and up to context unchanged lines are shown around each group of
+// changes. Each group of changes is introduced by a line giving the
+// starting line numbers and the number of lines in the expected and actual
+// strings, as follows:
+//
+//	@@ -expStart,expCount +actStart,actCount @@
+//
+// If the strings are the same an empty string is returned.
+func UnifiedDiff(act, exp string, context int) string {
+	if act == exp {
+		return ""
+	}
+
+	context = max(context, 0)
+
+	actLines := splitLines(act)
+	expLines := splitLines(exp)
+
+	ops, ok := myersOps(len(actLines), len(expLines),
+		func(ai, ei int) bool { return actLines[ai] == expLines[ei] })
+	if !ok {
+		ops = indexOps(len(actLines), len(expLines),
+			func(ai, ei int) bool { return actLines[ai] == expLines[ei] })
+	}
+
+	var b strings.Builder
+
+	b.WriteString("--- expected\n")
+	b.WriteString("+++ actual\n")
+
+	for _, h := range diffHunks(ops, context) {
+		writeHunk(&b, ops[h.start:h.end], actLines, expLines)
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// isMultiLine returns true if either string contains a newline
+func isMultiLine[S ~string](act, exp S) bool {
+	return strings.Contains(string(act), "\n") ||
+		strings.Contains(string(exp), "\n")
+}
+
+// splitLines splits the string into lines, each line retaining its
+// terminating newline (if any)
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// hunk records the range of editOps to be shown in a single group of
+// changes
+type hunk struct {
+	start int
+	end   int
+}
+
+// diffHunks returns the ranges of the editOps to be shown. Each range holds
+// one or more changes with up to context unchanged lines before and
+// after. Changes separated by no more than twice the context are shown in
+// the same range.
+func diffHunks(ops []editOp, context int) []hunk {
+	hunks := []hunk{}
+
+	for i, op := range ops {
+		if op.keep {
+			continue
+		}
+
+		start := max(i-context, 0)
+		end := min(i+context+1, len(ops))
+
+		if len(hunks) > 0 && start <= hunks[len(hunks)-1].end {
+			hunks[len(hunks)-1].end = end
+			continue
+		}
+
+		hunks = append(hunks, hunk{start: start, end: end})
+	}
+
+	return hunks
+}
+
+// writeHunk writes the header and the lines for a single group of
+// changes. Within each run of changes the deleted lines are shown before
+// the inserted lines.
+func writeHunk(b *strings.Builder, ops []editOp, actLines, expLines []string) {
+	var actCount, expCount int
+
+	for _, op := range ops {
+		if op.keep || op.op == EditInsert {
+			actCount++
+		}
+
+		if op.keep || op.op == EditDelete {
+			expCount++
+		}
+	}
+
+	fmt.Fprintf(b, "@@ -%s +%s @@\n",
+		hunkRange(ops[0].expIdx, expCount),
+		hunkRange(ops[0].actIdx, actCount))
+
+	for i := 0; i < len(ops); {
+		if ops[i].keep {
+			writeDiffLine(b, ' ', expLines[ops[i].expIdx])
+			i++
+
+			continue
+		}
+
+		var ins []string
+
+		for ; i < len(ops) && !ops[i].keep; i++ {
+			if ops[i].op == EditDelete {
+				writeDiffLine(b, '-', expLines[ops[i].expIdx])
+			} else {
+				ins = append(ins, actLines[ops[i].actIdx])
+			}
+		}
+
+		for _, line := range ins {
+			writeDiffLine(b, '+', line)
+		}
+	}
+}
+
+// hunkRange returns the line range for a hunk header. The idx is the
+// zero-based index of the first line. Following the usual convention, an
+// empty range gives the number of the line before the range.
+func hunkRange(idx, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", idx)
+	}
+
+	return fmt.Sprintf("%d,%d", idx+1, count)
+}
+
+// writeDiffLine writes the line with the given prefix. If the line is not
+// terminated by a newline a marker line is added after it.
+func writeDiffLine(b *strings.Builder, prefix byte, line string) {
+	b.WriteByte(prefix)
+
+	if s, ok := strings.CutSuffix(line, "\n"); ok {
+		b.WriteString(s)
+		b.WriteByte('\n')
+
+		return
+	}
+
+	b.WriteString(line)
+	b.WriteByte('\n')
+	b.WriteString(noNewlineMarker)
+	b.WriteByte('\n')
+}
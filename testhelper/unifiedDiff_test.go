@@ -0,0 +1,167 @@
+package testhelper_test
+
+import (
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		act, exp string
+		context  int
+		expDiff  string
+	}{
+		{
+			ID:  testhelper.MkID("same"),
+			act: "a\nb\n",
+			exp: "a\nb\n",
+		},
+		{
+			ID:      testhelper.MkID("single line changed"),
+			act:     "a\nB\nc\n",
+			exp:     "a\nb\nc\n",
+			context: 3,
+			expDiff: "--- expected\n" +
+				"+++ actual\n" +
+				"@@ -1,3 +1,3 @@\n" +
+				" a\n" +
+				"-b\n" +
+				"+B\n" +
+				" c",
+		},
+		{
+			ID:      testhelper.MkID("line inserted, limited context"),
+			act:     "1\n2\n3\n4\nX\n5\n6\n7\n8\n",
+			exp:     "1\n2\n3\n4\n5\n6\n7\n8\n",
+			context: 1,
+			expDiff: "--- expected\n" +
+				"+++ actual\n" +
+				"@@ -4,2 +4,3 @@\n" +
+				" 4\n" +
+				"+X\n" +
+				" 5",
+		},
+		{
+			ID:      testhelper.MkID("separate hunks"),
+			act:     "1\nA\n3\n4\n5\n6\n7\nB\n9\n",
+			exp:     "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			context: 1,
+			expDiff: "--- expected\n" +
+				"+++ actual\n" +
+				"@@ -1,3 +1,3 @@\n" +
+				" 1\n" +
+				"-2\n" +
+				"+A\n" +
+				" 3\n" +
+				"@@ -7,3 +7,3 @@\n" +
+				" 7\n" +
+				"-8\n" +
+				"+B\n" +
+				" 9",
+		},
+		{
+			ID:      testhelper.MkID("close changes, merged hunks"),
+			act:     "1\nA\n3\nB\n5\n",
+			exp:     "1\n2\n3\n4\n5\n",
+			context: 1,
+			expDiff: "--- expected\n" +
+				"+++ actual\n" +
+				"@@ -1,5 +1,5 @@\n" +
+				" 1\n" +
+				"-2\n" +
+				"+A\n" +
+				" 3\n" +
+				"-4\n" +
+				"+B\n" +
+				" 5",
+		},
+		{
+			ID:      testhelper.MkID("lines deleted, no context"),
+			act:     "1\n4\n",
+			exp:     "1\n2\n3\n4\n",
+			context: 0,
+			expDiff: "--- expected\n" +
+				"+++ actual\n" +
+				"@@ -2,2 +1,0 @@\n" +
+				"-2\n" +
+				"-3",
+		},
+		{
+			ID:      testhelper.MkID("missing final newline"),
+			act:     "a\nb",
+			exp:     "a\nb\n",
+			context: 3,
+			expDiff: "--- expected\n" +
+				"+++ actual\n" +
+				"@@ -1,2 +1,2 @@\n" +
+				" a\n" +
+				"-b\n" +
+				"+b\n" +
+				`\ No newline at end of file`,
+		},
+		{
+			ID:      testhelper.MkID("expected empty"),
+			act:     "a\nb\n",
+			context: 3,
+			expDiff: "--- expected\n" +
+				"+++ actual\n" +
+				"@@ -0,0 +1,2 @@\n" +
+				"+a\n" +
+				"+b",
+		},
+	}
+
+	for _, tc := range testCases {
+		diff := testhelper.UnifiedDiff(tc.act, tc.exp, tc.context)
+		testhelper.DiffString(t, tc.IDStr(), "diff", diff, tc.expDiff)
+	}
+}
+
+func TestDiffStringContext(t *testing.T) {
+	act := "a\nb\nc\nd\n"
+	exp := "a\nb\nX\nd\n"
+
+	testCases := []struct {
+		testhelper.ID
+		context int
+		expLog  string
+	}{
+		{
+			ID:      testhelper.MkID("no context"),
+			context: 0,
+			expLog: "id\n" +
+				"\t: str differs (expected length: 8, actual length: 8)\n" +
+				"\t: differences (-expected +actual):\n" +
+				"--- expected\n" +
+				"+++ actual\n" +
+				"@@ -3,1 +3,1 @@\n" +
+				"-X\n" +
+				"+c\n" +
+				"\t: str is incorrect\n",
+		},
+		{
+			ID:      testhelper.MkID("one line of context"),
+			context: 1,
+			expLog: "id\n" +
+				"\t: str differs (expected length: 8, actual length: 8)\n" +
+				"\t: differences (-expected +actual):\n" +
+				"--- expected\n" +
+				"+++ actual\n" +
+				"@@ -2,3 +2,3 @@\n" +
+				" b\n" +
+				"-X\n" +
+				"+c\n" +
+				" d\n" +
+				"\t: str is incorrect\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		ft := &fakeTB{TB: t}
+		ft.CheckResult(t, tc.IDStr(),
+			testhelper.DiffStringContext(ft, "id", "str", act, exp, tc.context),
+			tc.expLog)
+	}
+}
@@ -0,0 +1,435 @@
+package testhelper
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValPrinter controls how values are shown by its Sprint method. The
+// values are shown in a form close to Go syntax: structs are shown with
+// their field names, pointers are followed rather than shown as addresses
+// and map entries are shown in a deterministic order (numbers and strings
+// in ascending order, other keys by their type and then their
+// contents). Any loops through pointers, maps or slices are detected and
+// shown as <cycle>.
+//
+//	Indent, if not empty, causes the elements of slices, arrays, maps and
+//	structs to be shown one per line, indented by this string for each
+//	level of nesting. Otherwise the value is shown on a single line.
+//
+//	MaxElems, if greater than zero, limits the number of elements of
+//	slices, arrays and maps (and fields of structs) that are shown.
+//
+//	MaxDepth, if greater than zero, limits the depth of nesting of
+//	values that are shown. Any deeper values are shown as {...}
+//
+//	MaxStrLen, if greater than zero, limits the number of runes of
+//	strings that are shown.
+//
+//	UseStringMethods, if set, causes structs and pointers to structs
+//	having a String method to be shown using that method. For instance,
+//	this shows a time.Time value as its time and a *big.Int as its
+//	decimal value rather than their internal fields.
+//
+// The zero value shows the whole of the value on a single line which makes
+// it suitable for writing values to golden files. For a more readable
+// golden file set the Indent.
+type ValPrinter struct {
+	Indent           string
+	MaxElems         int
+	MaxDepth         int
+	MaxStrLen        int
+	UseStringMethods bool
+}
+
+// DefaultValPrinter is the ValPrinter used by the PrettyPrint func and to
+// show values in the reports of differences.
+var DefaultValPrinter = ValPrinter{
+	MaxElems:         20,
+	MaxDepth:         8,
+	MaxStrLen:        200,
+	UseStringMethods: true,
+}
+
+// PrettyPrint returns the value formatted by the DefaultValPrinter
+func PrettyPrint(v any) string {
+	return DefaultValPrinter.Sprint(v)
+}
+
+// prettyPrintVal returns the value formatted by the DefaultValPrinter
+func prettyPrintVal(v reflect.Value) string {
+	return DefaultValPrinter.sprintVal(v)
+}
+
+// Sprint returns the value formatted as described for the ValPrinter
+func (vp ValPrinter) Sprint(v any) string {
+	return vp.sprintVal(reflect.ValueOf(v))
+}
+
+// sprintVal returns the reflect.Value formatted as described for the
+// ValPrinter
+func (vp ValPrinter) sprintVal(v reflect.Value) string {
+	p := valPrinter{
+		vp:   vp,
+		seen: map[visit]bool{},
+	}
+
+	p.print(v, 0, true)
+
+	return p.b.String()
+}
+
+// valPrinter holds the state while a value is being printed
+type valPrinter struct {
+	vp   ValPrinter
+	b    strings.Builder
+	seen map[visit]bool
+}
+
+// enter records that the value is being printed. It returns false if the
+// value is already being printed (there is a loop) in which case it should
+// not be printed again.
+func (p *valPrinter) enter(v reflect.Value) bool {
+	vis := visit{actPtr: v.Pointer(), T: v.Type()}
+	if p.seen[vis] {
+		return false
+	}
+
+	p.seen[vis] = true
+
+	return true
+}
+
+// leave records that the value has been printed
+func (p *valPrinter) leave(v reflect.Value) {
+	delete(p.seen, visit{actPtr: v.Pointer(), T: v.Type()})
+}
+
+// print writes the value. The showType flag should be set if the type of
+// the value cannot be inferred from its context (such as when the value is
+// held in an interface).
+func (p *valPrinter) print(v reflect.Value, depth int, showType bool) {
+	if !v.IsValid() {
+		p.b.WriteString("nil")
+		return
+	}
+
+	if p.vp.UseStringMethods && isStructOrPtrToStruct(v) &&
+		p.printStringer(v) {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16,
+		reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8,
+		reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64,
+		reflect.Complex128, reflect.String:
+		p.printBasic(v, showType)
+	case reflect.Pointer:
+		p.printPointer(v, depth, showType)
+	case reflect.Interface:
+		if v.IsNil() {
+			p.b.WriteString("nil")
+			return
+		}
+
+		p.print(v.Elem(), depth, true)
+	case reflect.Slice:
+		p.printSlice(v, depth, showType)
+	case reflect.Array:
+		p.printElems(v, depth)
+	case reflect.Map:
+		p.printMap(v, depth, showType)
+	case reflect.Struct:
+		p.printStruct(v, depth)
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		p.printOpaque(v, showType)
+	case reflect.Invalid:
+	}
+}
+
+// isStructOrPtrToStruct returns true if the value is a struct or a non-nil
+// pointer to a struct
+func isStructOrPtrToStruct(v reflect.Value) bool {
+	if v.Kind() == reflect.Pointer && !v.IsNil() {
+		return v.Elem().Kind() == reflect.Struct
+	}
+
+	return v.Kind() == reflect.Struct
+}
+
+// printStringer writes the value using its String method if it has one. It
+// returns false if the value has no String method.
+func (p *valPrinter) printStringer(v reflect.Value) bool {
+	v, ok := accessible(v)
+	if !ok {
+		return false
+	}
+
+	s, isStringer := v.Interface().(fmt.Stringer)
+	if !isStringer {
+		return false
+	}
+
+	str, panicked := callString(s)
+	if panicked {
+		p.b.WriteString(str)
+		return true
+	}
+
+	p.b.WriteString(v.Type().String())
+	p.b.WriteByte('(')
+	p.printString(str)
+	p.b.WriteByte(')')
+
+	return true
+}
+
+// callString returns the result of the String method. If the method panics
+// the panic is recovered and the value is described as it would be by the
+// fmt package and panicked is set to true.
+func callString(s fmt.Stringer) (str string, panicked bool) {
+	defer func() {
+		if p := recover(); p != nil {
+			str = fmt.Sprintf("%%!v(PANIC=String method: %v)", p)
+			panicked = true
+		}
+	}()
+
+	return s.String(), false
+}
+
+// defaultTypes maps each kind to the type given to an untyped constant of
+// that kind. Values of these types need not be shown with their type.
+var defaultTypes = map[reflect.Kind]reflect.Type{
+	reflect.Bool:       reflect.TypeFor[bool](),
+	reflect.Int:        reflect.TypeFor[int](),
+	reflect.Float64:    reflect.TypeFor[float64](),
+	reflect.Complex128: reflect.TypeFor[complex128](),
+	reflect.String:     reflect.TypeFor[string](),
+}
+
+// printBasic writes the value of a bool, number or string
+func (p *valPrinter) printBasic(v reflect.Value, showType bool) {
+	showType = showType && defaultTypes[v.Kind()] != v.Type()
+	if showType {
+		p.b.WriteString(v.Type().String())
+		p.b.WriteByte('(')
+	}
+
+	switch v.Kind() { //nolint:exhaustive
+	case reflect.Bool:
+		p.b.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16,
+		reflect.Int32, reflect.Int64:
+		p.b.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		p.b.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		p.b.WriteString(fmtFloat(v.Float(), v.Type().Bits()))
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		bits := v.Type().Bits() / 2 //nolint:mnd
+
+		p.b.WriteString("complex(" +
+			fmtFloat(real(c), bits) + ", " + fmtFloat(imag(c), bits) + ")")
+	case reflect.String:
+		p.printString(v.String())
+	}
+
+	if showType {
+		p.b.WriteByte(')')
+	}
+}
+
+// fmtFloat returns the float formatted as a Go expression
+func fmtFloat(f float64, bits int) string {
+	switch {
+	case math.IsNaN(f):
+		return "math.NaN()"
+	case math.IsInf(f, 1):
+		return "math.Inf(1)"
+	case math.IsInf(f, -1):
+		return "math.Inf(-1)"
+	}
+
+	return strconv.FormatFloat(f, 'g', -1, bits)
+}
+
+// printString writes the string as a quoted string, truncated if
+// necessary
+func (p *valPrinter) printString(s string) {
+	if p.vp.MaxStrLen <= 0 || utf8.RuneCountInString(s) <= p.vp.MaxStrLen {
+		p.b.WriteString(strconv.Quote(s))
+		return
+	}
+
+	runes := []rune(s)
+	p.b.WriteString(strconv.Quote(string(runes[:p.vp.MaxStrLen])))
+	fmt.Fprintf(&p.b, "... (%d more runes)", len(runes)-p.vp.MaxStrLen)
+}
+
+// printNil writes a nil value, converted to its type if necessary
+func (p *valPrinter) printNil(v reflect.Value, showType bool) {
+	if showType {
+		p.b.WriteString("(" + v.Type().String() + ")(nil)")
+		return
+	}
+
+	p.b.WriteString("nil")
+}
+
+// printOpaque writes a chan, func or unsafe pointer. Their addresses are
+// not shown so that the results are reproducible.
+func (p *valPrinter) printOpaque(v reflect.Value, showType bool) {
+	if v.IsNil() {
+		p.printNil(v, showType)
+		return
+	}
+
+	p.b.WriteString("<" + v.Type().String() + ">")
+}
+
+// printPointer writes the value pointed to, preceded by an '&'
+func (p *valPrinter) printPointer(v reflect.Value, depth int, showType bool) {
+	if v.IsNil() {
+		p.printNil(v, showType)
+		return
+	}
+
+	if !p.enter(v) {
+		p.b.WriteString("<cycle>")
+		return
+	}
+	defer p.leave(v)
+
+	p.b.WriteByte('&')
+	p.print(v.Elem(), depth, true)
+}
+
+// printSlice writes the slice value
+func (p *valPrinter) printSlice(v reflect.Value, depth int, showType bool) {
+	if v.IsNil() {
+		p.printNil(v, showType)
+		return
+	}
+
+	if !p.enter(v) {
+		p.b.WriteString("<cycle>")
+		return
+	}
+	defer p.leave(v)
+
+	p.printElems(v, depth)
+}
+
+// printElems writes the elements of a slice or array value
+func (p *valPrinter) printElems(v reflect.Value, depth int) {
+	showType := v.Type().Elem().Kind() == reflect.Interface
+
+	p.printComposite(v.Type(), v.Len(), depth, func(i int) {
+		p.print(v.Index(i), depth+1, showType)
+	})
+}
+
+// printMap writes the map value with its keys in a deterministic order
+func (p *valPrinter) printMap(v reflect.Value, depth int, showType bool) {
+	if v.IsNil() {
+		p.printNil(v, showType)
+		return
+	}
+
+	if !p.enter(v) {
+		p.b.WriteString("<cycle>")
+		return
+	}
+	defer p.leave(v)
+
+	keys := sortedMapKeys(v)
+	keyShowType := v.Type().Key().Kind() == reflect.Interface
+	valShowType := v.Type().Elem().Kind() == reflect.Interface
+
+	p.printComposite(v.Type(), len(keys), depth, func(i int) {
+		p.print(keys[i], depth+1, keyShowType)
+		p.b.WriteString(": ")
+		p.print(v.MapIndex(keys[i]), depth+1, valShowType)
+	})
+}
+
+// printStruct writes the struct value with its field names
+func (p *valPrinter) printStruct(v reflect.Value, depth int) {
+	t := v.Type()
+
+	p.printComposite(t, v.NumField(), depth, func(i int) {
+		f := t.Field(i)
+		p.b.WriteString(f.Name + ": ")
+		p.print(v.Field(i), depth+1, f.Type.Kind() == reflect.Interface)
+	})
+}
+
+// printComposite writes the type followed by the n elements, each written
+// by the printElem func, in braces. The elements are written on separate
+// lines if the ValPrinter has an Indent. The number of elements and the
+// depth of nesting are limited as given by the ValPrinter.
+func (p *valPrinter) printComposite(t reflect.Type, n, depth int,
+	printElem func(i int),
+) {
+	p.b.WriteString(t.String())
+
+	if n == 0 {
+		p.b.WriteString("{}")
+		return
+	}
+
+	if p.vp.MaxDepth > 0 && depth >= p.vp.MaxDepth {
+		p.b.WriteString("{...}")
+		return
+	}
+
+	shown := n
+	if p.vp.MaxElems > 0 {
+		shown = min(n, p.vp.MaxElems)
+	}
+
+	p.b.WriteByte('{')
+
+	for i := range shown {
+		p.startElem(i, depth)
+		printElem(i)
+	}
+
+	if shown < n {
+		p.startElem(shown, depth)
+		fmt.Fprintf(&p.b, "... (%d more)", n-shown)
+	}
+
+	if p.vp.Indent != "" {
+		p.b.WriteString(",\n" + strings.Repeat(p.vp.Indent, depth))
+	}
+
+	p.b.WriteByte('}')
+}
+
+// startElem writes the separator before the i'th element of a composite
+// value
+func (p *valPrinter) startElem(i, depth int) {
+	if p.vp.Indent != "" {
+		if i > 0 {
+			p.b.WriteByte(',')
+		}
+
+		p.b.WriteString("\n" + strings.Repeat(p.vp.Indent, depth+1))
+
+		return
+	}
+
+	if i > 0 {
+		p.b.WriteString(", ")
+	}
+}
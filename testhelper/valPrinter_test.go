@@ -0,0 +1,217 @@
+package testhelper_test
+
+import (
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+// vpPoint exists only to test the behaviour of the ValPrinter
+type vpPoint struct {
+	X, Y int
+}
+
+// vpNode exists only to test the behaviour of the ValPrinter
+type vpNode struct {
+	Name string
+	Next *vpNode
+	Data any
+}
+
+// vpCount exists only to test the behaviour of the ValPrinter
+type vpCount int
+
+// vpPanicky exists only to test the behaviour of the ValPrinter. Its String
+// method panics if the pointer is nil.
+type vpPanicky struct {
+	p *int
+}
+
+func (v vpPanicky) String() string { return strconv.Itoa(*v.p) }
+
+func TestValPrinter(t *testing.T) {
+	loop := &vpNode{Name: "loop"}
+	loop.Next = loop
+
+	shared := &vpPoint{X: 1, Y: 2}
+
+	var (
+		nilPtr   *vpPoint
+		nilSlice []int
+		nilMap   map[string]int
+		nilFunc  func()
+	)
+
+	testCases := []struct {
+		testhelper.ID
+		vp     testhelper.ValPrinter
+		val    any
+		expStr string
+	}{
+		{
+			ID:     testhelper.MkID("nil"),
+			expStr: "nil",
+		},
+		{
+			ID:     testhelper.MkID("int"),
+			val:    42,
+			expStr: "42",
+		},
+		{
+			ID:     testhelper.MkID("int64"),
+			val:    int64(42),
+			expStr: "int64(42)",
+		},
+		{
+			ID:     testhelper.MkID("named int"),
+			val:    vpCount(3),
+			expStr: "testhelper_test.vpCount(3)",
+		},
+		{
+			ID:     testhelper.MkID("float"),
+			val:    1.5,
+			expStr: "1.5",
+		},
+		{
+			ID:     testhelper.MkID("NaN"),
+			val:    math.NaN(),
+			expStr: "math.NaN()",
+		},
+		{
+			ID:     testhelper.MkID("complex"),
+			val:    complex(1, -2),
+			expStr: "complex(1, -2)",
+		},
+		{
+			ID:     testhelper.MkID("string"),
+			val:    "a\tb",
+			expStr: `"a\tb"`,
+		},
+		{
+			ID:     testhelper.MkID("nil pointer"),
+			val:    nilPtr,
+			expStr: "(*testhelper_test.vpPoint)(nil)",
+		},
+		{
+			ID:     testhelper.MkID("nil slice"),
+			val:    nilSlice,
+			expStr: "([]int)(nil)",
+		},
+		{
+			ID:     testhelper.MkID("nil map"),
+			val:    nilMap,
+			expStr: "(map[string]int)(nil)",
+		},
+		{
+			ID:     testhelper.MkID("nil func"),
+			val:    nilFunc,
+			expStr: "(func())(nil)",
+		},
+		{
+			ID:     testhelper.MkID("func"),
+			val:    myFunc,
+			expStr: "<func() int>",
+		},
+		{
+			ID:     testhelper.MkID("struct"),
+			val:    vpPoint{X: 1, Y: 2},
+			expStr: "testhelper_test.vpPoint{X: 1, Y: 2}",
+		},
+		{
+			ID:     testhelper.MkID("unexported fields"),
+			val:    myStructSimple{i: 1, f: 2.5},
+			expStr: "testhelper_test.myStructSimple{i: 1, f: 2.5}",
+		},
+		{
+			ID:     testhelper.MkID("pointer"),
+			val:    &vpPoint{X: 1},
+			expStr: "&testhelper_test.vpPoint{X: 1, Y: 0}",
+		},
+		{
+			ID:     testhelper.MkID("slice of interfaces"),
+			val:    []any{1, "a", uint8(2), nil},
+			expStr: `[]interface {}{1, "a", uint8(2), nil}`,
+		},
+		{
+			ID:     testhelper.MkID("empty slice"),
+			val:    []int{},
+			expStr: "[]int{}",
+		},
+		{
+			ID:     testhelper.MkID("array"),
+			val:    [2]bool{true, false},
+			expStr: "[2]bool{true, false}",
+		},
+		{
+			ID:     testhelper.MkID("map, sorted keys"),
+			val:    map[string]int{"c": 3, "a": 1, "b": 2},
+			expStr: `map[string]int{"a": 1, "b": 2, "c": 3}`,
+		},
+		{
+			ID:  testhelper.MkID("shared pointer, not a cycle"),
+			val: []*vpPoint{shared, shared},
+			expStr: "[]*testhelper_test.vpPoint{" +
+				"&testhelper_test.vpPoint{X: 1, Y: 2}, " +
+				"&testhelper_test.vpPoint{X: 1, Y: 2}}",
+		},
+		{
+			ID:  testhelper.MkID("cycle"),
+			val: loop,
+			expStr: `&testhelper_test.vpNode{Name: "loop",` +
+				` Next: <cycle>, Data: nil}`,
+		},
+		{
+			ID:     testhelper.MkID("String method"),
+			vp:     testhelper.ValPrinter{UseStringMethods: true},
+			val:    time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+			expStr: `time.Time("2020-01-02 03:04:05 +0000 UTC")`,
+		},
+		{
+			ID:  testhelper.MkID("String method panics"),
+			vp:  testhelper.ValPrinter{UseStringMethods: true},
+			val: []vpPanicky{{}},
+			expStr: "[]testhelper_test.vpPanicky{%!v(PANIC=String method:" +
+				" runtime error: invalid memory address" +
+				" or nil pointer dereference)}",
+		},
+		{
+			ID:     testhelper.MkID("MaxElems"),
+			vp:     testhelper.ValPrinter{MaxElems: 2},
+			val:    []int{1, 2, 3, 4},
+			expStr: "[]int{1, 2, ... (2 more)}",
+		},
+		{
+			ID:  testhelper.MkID("MaxDepth"),
+			vp:  testhelper.ValPrinter{MaxDepth: 1},
+			val: vpNode{Name: "a", Data: []int{1}},
+			expStr: `testhelper_test.vpNode{Name: "a", Next: nil,` +
+				` Data: []int{...}}`,
+		},
+		{
+			ID:     testhelper.MkID("MaxStrLen"),
+			vp:     testhelper.ValPrinter{MaxStrLen: 3},
+			val:    "abcdef",
+			expStr: `"abc"... (3 more runes)`,
+		},
+		{
+			ID:  testhelper.MkID("Indent"),
+			vp:  testhelper.ValPrinter{Indent: "\t"},
+			val: map[string][]int{"a": {1, 2}, "b": {}},
+			expStr: "map[string][]int{\n" +
+				"\t\"a\": []int{\n" +
+				"\t\t1,\n" +
+				"\t\t2,\n" +
+				"\t},\n" +
+				"\t\"b\": []int{},\n" +
+				"}",
+		},
+	}
+
+	for _, tc := range testCases {
+		testhelper.DiffString(t, tc.IDStr(), "printed value",
+			tc.vp.Sprint(tc.val), tc.expStr)
+	}
+}